@@ -0,0 +1,95 @@
+// Command msgpackdump prints MessagePack streams in a readable form and
+// converts between MessagePack and JSON.
+//
+// Usage:
+//
+//	msgpackdump [-json | -fromjson] [-maxstring n] [-maxvalue n] [-maxdepth n] [file]
+//
+// Msgpackdump reads file (default standard input) and writes to standard
+// output. By default, msgpackdump prints one line per value with the byte
+// offset, the MessagePack format and the value as described for msgpack.Dump.
+// Nested values are indented.
+//
+// The -json flag converts each MessagePack value to a line of JSON as
+// described for msgpack.ToJSON. The -fromjson flag converts a stream of JSON
+// values to MessagePack as described for msgpack.FromJSON.
+//
+// The -maxstring, -maxvalue and -maxdepth flags set the limits on the input
+// described for msgpack.Limits. The defaults are msgpack.DefaultDumpLimits. A
+// limit of zero is not checked, except that nesting is always limited to 10000
+// levels.
+//
+// For example, to inspect RPC traffic captured from Nvim:
+//
+//	msgpackdump capture.bin
+//	msgpackdump -json capture.bin | jq .
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/neovim/go-client/msgpack"
+)
+
+var (
+	toJSON    = flag.Bool("json", false, "convert MessagePack to JSON")
+	fromJSON  = flag.Bool("fromjson", false, "convert JSON to MessagePack")
+	maxString = flag.Int("maxstring", msgpack.DefaultDumpLimits.MaxStringLen, "maximum length of strings, binary and extension data")
+	maxValue  = flag.Int("maxvalue", msgpack.DefaultDumpLimits.MaxValueBytes, "maximum size in bytes of a top-level MessagePack value")
+	maxDepth  = flag.Int("maxdepth", msgpack.DefaultDumpLimits.MaxDepth, "maximum nesting depth of arrays and maps")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of msgpackdump:\n")
+	fmt.Fprintf(os.Stderr, "\tmsgpackdump [-json | -fromjson] [-maxstring n] [-maxvalue n] [-maxdepth n] [file]\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("msgpackdump: ")
+	flag.Usage = usage
+	flag.Parse()
+	if *toJSON && *fromJSON || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var r io.Reader = os.Stdin
+	if flag.NArg() == 1 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	convert := msgpack.Dump
+	switch {
+	case *toJSON:
+		convert = msgpack.ToJSON
+	case *fromJSON:
+		convert = msgpack.FromJSON
+	}
+
+	limits := msgpack.Limits{
+		MaxStringLen:  *maxString,
+		MaxValueBytes: *maxValue,
+		MaxDepth:      *maxDepth,
+	}
+	w := bufio.NewWriter(os.Stdout)
+	err := convert(w, r, &limits)
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
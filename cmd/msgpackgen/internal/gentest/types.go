@@ -0,0 +1,112 @@
+// Package gentest contains types for testing the code generated by
+// msgpackgen.
+package gentest
+
+import (
+	"strings"
+	"time"
+
+	"github.com/neovim/go-client/msgpack"
+)
+
+//go:generate go run github.com/neovim/go-client/cmd/msgpackgen -type Basic,Tagged,Array,Embed,Containers,Mode,List -output types_msgpack.go
+
+type Mode int
+
+type Basic struct {
+	Bool    bool
+	Int     int
+	Int8    int8
+	Int16   int16
+	Int32   int32
+	Int64   int64
+	Uint    uint
+	Uint8   uint8
+	Uint16  uint16
+	Uint32  uint32
+	Uint64  uint64
+	Float32 float32
+	Float64 float64
+	String  string
+	Bytes   []byte
+	Time    time.Time
+	Mode    Mode
+	Any     interface{}
+}
+
+type Tagged struct {
+	A          int       `msgpack:"a,omitempty"`
+	B          string    `msgpack:"b,omitempty" empty:"none"`
+	C          bool      `msgpack:"c" empty:"true"`
+	D          *int      `msgpack:",omitempty"`
+	E          []string  `msgpack:",omitempty"`
+	T          time.Time `msgpack:",omitempty"`
+	Skip       int       `msgpack:"-"`
+	unexported int
+}
+
+type Array struct {
+	X int `msgpack:",array"`
+	Y string
+	Z []int
+}
+
+type Base struct {
+	ID   int
+	Name string
+}
+
+type Embed struct {
+	Base
+	Ext   Ext                    `msgpack:"ext"`
+	Meta  Meta                   `msgpack:",inline"`
+	Extra map[string]interface{} `msgpack:",remain"`
+}
+
+type Meta struct {
+	Tag string `msgpack:"tag,omitempty"`
+}
+
+// Ext has hand-written methods with pointer receivers.
+type Ext struct {
+	S string
+}
+
+func (x *Ext) MarshalMsgPack(enc *msgpack.Encoder) error {
+	return enc.PackString(x.S)
+}
+
+func (x *Ext) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	s, err := dec.ConvertString()
+	x.S = s
+	return err
+}
+
+type Containers struct {
+	Ints   []int
+	Array  [3]uint16
+	Map    map[string]int
+	Ptr    *Basic
+	PtrInt *int
+	Arrays []Array
+	List   List
+	Nested map[string][]*Array
+	Any    map[string]interface{}
+	Ext    map[int]Ext
+	Label  Label
+	Labels map[Label]int
+}
+
+type List []Array
+
+// Label is encoded with its text marshaling methods.
+type Label string
+
+func (l Label) MarshalText() ([]byte, error) {
+	return []byte("label:" + l), nil
+}
+
+func (l *Label) UnmarshalText(p []byte) error {
+	*l = Label(strings.TrimPrefix(string(p), "label:"))
+	return nil
+}
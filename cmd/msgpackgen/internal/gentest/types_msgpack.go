@@ -0,0 +1,1523 @@
+// Code generated by "msgpackgen -type Basic,Tagged,Array,Embed,Containers,Mode,List -output types_msgpack.go"; DO NOT EDIT.
+
+package gentest
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/neovim/go-client/msgpack"
+)
+
+// MarshalMsgPack implements the msgpack.Marshaler interface.
+func (x Basic) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if err := enc.PackMapLen(18); err != nil {
+		return err
+	}
+	if err := enc.PackString("Bool"); err != nil {
+		return err
+	}
+	if err := enc.PackBool(x.Bool); err != nil {
+		return err
+	}
+	if err := enc.PackString("Int"); err != nil {
+		return err
+	}
+	if err := enc.PackInt(int64(x.Int)); err != nil {
+		return err
+	}
+	if err := enc.PackString("Int8"); err != nil {
+		return err
+	}
+	if err := enc.PackInt(int64(x.Int8)); err != nil {
+		return err
+	}
+	if err := enc.PackString("Int16"); err != nil {
+		return err
+	}
+	if err := enc.PackInt(int64(x.Int16)); err != nil {
+		return err
+	}
+	if err := enc.PackString("Int32"); err != nil {
+		return err
+	}
+	if err := enc.PackInt(int64(x.Int32)); err != nil {
+		return err
+	}
+	if err := enc.PackString("Int64"); err != nil {
+		return err
+	}
+	if err := enc.PackInt(x.Int64); err != nil {
+		return err
+	}
+	if err := enc.PackString("Uint"); err != nil {
+		return err
+	}
+	if err := enc.PackUint(uint64(x.Uint)); err != nil {
+		return err
+	}
+	if err := enc.PackString("Uint8"); err != nil {
+		return err
+	}
+	if err := enc.PackUint(uint64(x.Uint8)); err != nil {
+		return err
+	}
+	if err := enc.PackString("Uint16"); err != nil {
+		return err
+	}
+	if err := enc.PackUint(uint64(x.Uint16)); err != nil {
+		return err
+	}
+	if err := enc.PackString("Uint32"); err != nil {
+		return err
+	}
+	if err := enc.PackUint(uint64(x.Uint32)); err != nil {
+		return err
+	}
+	if err := enc.PackString("Uint64"); err != nil {
+		return err
+	}
+	if err := enc.PackUint(x.Uint64); err != nil {
+		return err
+	}
+	if err := enc.PackString("Float32"); err != nil {
+		return err
+	}
+	if err := enc.PackFloat32(x.Float32); err != nil {
+		return err
+	}
+	if err := enc.PackString("Float64"); err != nil {
+		return err
+	}
+	if err := enc.PackFloat(x.Float64); err != nil {
+		return err
+	}
+	if err := enc.PackString("String"); err != nil {
+		return err
+	}
+	if err := enc.PackString(x.String); err != nil {
+		return err
+	}
+	if err := enc.PackString("Bytes"); err != nil {
+		return err
+	}
+	if err := enc.PackBinary(x.Bytes); err != nil {
+		return err
+	}
+	if err := enc.PackString("Time"); err != nil {
+		return err
+	}
+	if err := enc.PackTimestamp(x.Time); err != nil {
+		return err
+	}
+	if err := enc.PackString("Mode"); err != nil {
+		return err
+	}
+	if err := x.Mode.MarshalMsgPack(enc); err != nil {
+		return err
+	}
+	if err := enc.PackString("Any"); err != nil {
+		return err
+	}
+	if err := enc.Encode(x.Any); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.
+func (x *Basic) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	if dec.UnknownFieldsDisallowed() || dec.FieldOptions() != (msgpack.FieldOptions{}) {
+		return dec.DecodeStruct(x)
+	}
+	var errSaved error
+	saveError := func(err error) error {
+		switch err.(type) {
+		case *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+			if errSaved == nil {
+				errSaved = err
+			}
+			return nil
+		}
+		return err
+	}
+	if dec.Type() != msgpack.MapLen {
+		if err := saveError(dec.ConvertError(reflect.TypeOf(x).Elem())); err != nil {
+			return err
+		}
+		return errSaved
+	}
+	for i0, n1 := 0, dec.Len(); i0 < n1; i0++ {
+		if err := dec.Unpack(); err != nil {
+			return err
+		}
+		if t := dec.Type(); t != msgpack.String && t != msgpack.Binary {
+			if err := saveError(dec.ConvertError(reflect.TypeOf(""))); err != nil {
+				return err
+			}
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		switch string(dec.BytesNoCopy()) {
+		case "Bool":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertBool(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Bool = v
+			}
+		case "Int":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertInt(0); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Int = int(v)
+			}
+		case "Int8":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertInt(8); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Int8 = int8(v)
+			}
+		case "Int16":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertInt(16); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Int16 = int16(v)
+			}
+		case "Int32":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertInt(32); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Int32 = int32(v)
+			}
+		case "Int64":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertInt(64); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Int64 = v
+			}
+		case "Uint":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertUint(0); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Uint = uint(v)
+			}
+		case "Uint8":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertUint(8); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Uint8 = uint8(v)
+			}
+		case "Uint16":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertUint(16); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Uint16 = uint16(v)
+			}
+		case "Uint32":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertUint(32); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Uint32 = uint32(v)
+			}
+		case "Uint64":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertUint(64); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Uint64 = v
+			}
+		case "Float32":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertFloat(32); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Float32 = float32(v)
+			}
+		case "Float64":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertFloat(64); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Float64 = v
+			}
+		case "String":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertString(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.String = v
+			}
+		case "Bytes":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertBytes(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Bytes = v
+			}
+		case "Time":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertTime(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Time = v
+			}
+		case "Mode":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := saveError(x.Mode.UnmarshalMsgPack(dec)); err != nil {
+				return err
+			}
+		case "Any":
+			if err := saveError(dec.Decode(&x.Any)); err != nil {
+				return err
+			}
+		default:
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return errSaved
+}
+
+// MarshalMsgPack implements the msgpack.Marshaler interface.
+func (x Tagged) MarshalMsgPack(enc *msgpack.Encoder) error {
+	n := int64(2)
+	if x.A != 0 {
+		n++
+	}
+	if x.B != "none" {
+		n++
+	}
+	if x.D != nil {
+		n++
+	}
+	if len(x.E) != 0 {
+		n++
+	}
+	if err := enc.PackMapLen(n); err != nil {
+		return err
+	}
+	if x.A != 0 {
+		if err := enc.PackString("a"); err != nil {
+			return err
+		}
+		if err := enc.PackInt(int64(x.A)); err != nil {
+			return err
+		}
+	}
+	if x.B != "none" {
+		if err := enc.PackString("b"); err != nil {
+			return err
+		}
+		if err := enc.PackString(x.B); err != nil {
+			return err
+		}
+	}
+	if err := enc.PackString("c"); err != nil {
+		return err
+	}
+	if err := enc.PackBool(x.C); err != nil {
+		return err
+	}
+	if x.D != nil {
+		if err := enc.PackString("D"); err != nil {
+			return err
+		}
+		if x.D == nil {
+			if err := enc.PackNil(); err != nil {
+				return err
+			}
+		} else {
+			if err := enc.PackInt(int64(*x.D)); err != nil {
+				return err
+			}
+		}
+	}
+	if len(x.E) != 0 {
+		if err := enc.PackString("E"); err != nil {
+			return err
+		}
+		if x.E == nil {
+			if err := enc.PackNil(); err != nil {
+				return err
+			}
+		} else {
+			if err := enc.PackArrayLen(int64(len(x.E))); err != nil {
+				return err
+			}
+			for i0 := range x.E {
+				if err := enc.PackString(x.E[i0]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := enc.PackString("T"); err != nil {
+		return err
+	}
+	if err := enc.PackTimestamp(x.T); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.
+func (x *Tagged) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	if dec.UnknownFieldsDisallowed() || dec.FieldOptions() != (msgpack.FieldOptions{}) {
+		return dec.DecodeStruct(x)
+	}
+	var errSaved error
+	saveError := func(err error) error {
+		switch err.(type) {
+		case *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+			if errSaved == nil {
+				errSaved = err
+			}
+			return nil
+		}
+		return err
+	}
+	x.B = "none"
+	x.C = true
+	if dec.Type() != msgpack.MapLen {
+		if err := saveError(dec.ConvertError(reflect.TypeOf(x).Elem())); err != nil {
+			return err
+		}
+		return errSaved
+	}
+	for i0, n1 := 0, dec.Len(); i0 < n1; i0++ {
+		if err := dec.Unpack(); err != nil {
+			return err
+		}
+		if t := dec.Type(); t != msgpack.String && t != msgpack.Binary {
+			if err := saveError(dec.ConvertError(reflect.TypeOf(""))); err != nil {
+				return err
+			}
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		switch string(dec.BytesNoCopy()) {
+		case "a":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertInt(0); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.A = int(v)
+			}
+		case "b":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertString(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.B = v
+			}
+		case "c":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertBool(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.C = v
+			}
+		case "D":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if dec.Type() == msgpack.Nil {
+				x.D = nil
+			} else {
+				if x.D == nil {
+					x.D = new(int)
+				}
+				if v, err := dec.ConvertInt(0); err != nil {
+					if err := saveError(err); err != nil {
+						return err
+					}
+				} else {
+					*x.D = int(v)
+				}
+			}
+		case "E":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			switch dec.Type() {
+			case msgpack.Nil:
+				x.E = x.E[:0]
+			case msgpack.ArrayLen:
+				if n3 := dec.Len(); n3 > cap(x.E) {
+					s4 := make([]string, n3)
+					copy(s4, x.E)
+					x.E = s4
+				} else {
+					x.E = x.E[:n3]
+				}
+				for i2 := range x.E {
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if v, err := dec.ConvertString(); err != nil {
+						if err := saveError(err); err != nil {
+							return err
+						}
+					} else {
+						x.E[i2] = v
+					}
+				}
+			default:
+				if err := saveError(dec.ConvertError(reflect.TypeOf(x.E))); err != nil {
+					return err
+				}
+			}
+		case "T":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertTime(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.T = v
+			}
+		default:
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return errSaved
+}
+
+// MarshalMsgPack implements the msgpack.Marshaler interface.
+func (x Array) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if err := enc.PackArrayLen(3); err != nil {
+		return err
+	}
+	if err := enc.PackInt(int64(x.X)); err != nil {
+		return err
+	}
+	if err := enc.PackString(x.Y); err != nil {
+		return err
+	}
+	if x.Z == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.PackArrayLen(int64(len(x.Z))); err != nil {
+			return err
+		}
+		for i0 := range x.Z {
+			if err := enc.PackInt(int64(x.Z[i0])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.
+func (x *Array) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	if dec.UnknownFieldsDisallowed() || dec.FieldOptions() != (msgpack.FieldOptions{}) {
+		return dec.DecodeStruct(x)
+	}
+	var errSaved error
+	saveError := func(err error) error {
+		switch err.(type) {
+		case *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+			if errSaved == nil {
+				errSaved = err
+			}
+			return nil
+		}
+		return err
+	}
+	if dec.Type() != msgpack.ArrayLen {
+		if err := saveError(dec.ConvertError(reflect.TypeOf(x).Elem())); err != nil {
+			return err
+		}
+		return errSaved
+	}
+	for i0, n1 := 0, dec.Len(); i0 < n1; i0++ {
+		switch i0 {
+		case 0:
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertInt(0); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.X = int(v)
+			}
+		case 1:
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertString(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Y = v
+			}
+		case 2:
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			switch dec.Type() {
+			case msgpack.Nil:
+				x.Z = x.Z[:0]
+			case msgpack.ArrayLen:
+				if n3 := dec.Len(); n3 > cap(x.Z) {
+					s4 := make([]int, n3)
+					copy(s4, x.Z)
+					x.Z = s4
+				} else {
+					x.Z = x.Z[:n3]
+				}
+				for i2 := range x.Z {
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if v, err := dec.ConvertInt(0); err != nil {
+						if err := saveError(err); err != nil {
+							return err
+						}
+					} else {
+						x.Z[i2] = int(v)
+					}
+				}
+			default:
+				if err := saveError(dec.ConvertError(reflect.TypeOf(x.Z))); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return errSaved
+}
+
+// MarshalMsgPack implements the msgpack.Marshaler interface.
+func (x Embed) MarshalMsgPack(enc *msgpack.Encoder) error {
+	var rest0 []string
+	for k1 := range x.Extra {
+		switch k1 {
+		case "ID", "Name", "ext", "tag":
+			continue
+		}
+		rest0 = append(rest0, k1)
+	}
+	if enc.SortMapKeys() {
+		sort.Slice(rest0, func(i, j int) bool { return rest0[i] < rest0[j] })
+	}
+	n := int64(3 + len(rest0))
+	if len(x.Meta.Tag) != 0 {
+		n++
+	}
+	if err := enc.PackMapLen(n); err != nil {
+		return err
+	}
+	if err := enc.PackString("ID"); err != nil {
+		return err
+	}
+	if err := enc.PackInt(int64(x.Base.ID)); err != nil {
+		return err
+	}
+	if err := enc.PackString("Name"); err != nil {
+		return err
+	}
+	if err := enc.PackString(x.Base.Name); err != nil {
+		return err
+	}
+	if err := enc.PackString("ext"); err != nil {
+		return err
+	}
+	if err := x.Ext.MarshalMsgPack(enc); err != nil {
+		return err
+	}
+	if len(x.Meta.Tag) != 0 {
+		if err := enc.PackString("tag"); err != nil {
+			return err
+		}
+		if err := enc.PackString(x.Meta.Tag); err != nil {
+			return err
+		}
+	}
+	for _, k2 := range rest0 {
+		if err := enc.PackString(k2); err != nil {
+			return err
+		}
+		if err := enc.Encode(x.Extra[k2]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.
+func (x *Embed) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	if dec.UnknownFieldsDisallowed() || dec.FieldOptions() != (msgpack.FieldOptions{}) {
+		return dec.DecodeStruct(x)
+	}
+	var errSaved error
+	saveError := func(err error) error {
+		switch err.(type) {
+		case *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+			if errSaved == nil {
+				errSaved = err
+			}
+			return nil
+		}
+		return err
+	}
+	if dec.Type() != msgpack.MapLen {
+		if err := saveError(dec.ConvertError(reflect.TypeOf(x).Elem())); err != nil {
+			return err
+		}
+		return errSaved
+	}
+	for i0, n1 := 0, dec.Len(); i0 < n1; i0++ {
+		if err := dec.Unpack(); err != nil {
+			return err
+		}
+		if t := dec.Type(); t != msgpack.String && t != msgpack.Binary {
+			if err := saveError(dec.ConvertError(reflect.TypeOf(""))); err != nil {
+				return err
+			}
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		switch string(dec.BytesNoCopy()) {
+		case "ID":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertInt(0); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Base.ID = int(v)
+			}
+		case "Name":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertString(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Base.Name = v
+			}
+		case "ext":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := saveError(x.Ext.UnmarshalMsgPack(dec)); err != nil {
+				return err
+			}
+		case "tag":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if v, err := dec.ConvertString(); err != nil {
+				if err := saveError(err); err != nil {
+					return err
+				}
+			} else {
+				x.Meta.Tag = v
+			}
+		default:
+			if x.Extra == nil {
+				x.Extra = make(map[string]interface{})
+			}
+			k2 := dec.String()
+			var v3 interface{}
+			if err := saveError(dec.Decode(&v3)); err != nil {
+				return err
+			}
+			x.Extra[k2] = v3
+		}
+	}
+	return errSaved
+}
+
+// MarshalMsgPack implements the msgpack.Marshaler interface.
+func (x Containers) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if err := enc.PackMapLen(12); err != nil {
+		return err
+	}
+	if err := enc.PackString("Ints"); err != nil {
+		return err
+	}
+	if x.Ints == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.PackArrayLen(int64(len(x.Ints))); err != nil {
+			return err
+		}
+		for i0 := range x.Ints {
+			if err := enc.PackInt(int64(x.Ints[i0])); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.PackString("Array"); err != nil {
+		return err
+	}
+	if err := enc.PackArrayLen(int64(len(x.Array))); err != nil {
+		return err
+	}
+	for i1 := range x.Array {
+		if err := enc.PackUint(uint64(x.Array[i1])); err != nil {
+			return err
+		}
+	}
+	if err := enc.PackString("Map"); err != nil {
+		return err
+	}
+	if x.Map == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else if enc.SortMapKeys() {
+		if err := enc.Encode(x.Map); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.PackMapLen(int64(len(x.Map))); err != nil {
+			return err
+		}
+		for k2, v3 := range x.Map {
+			if err := enc.PackString(k2); err != nil {
+				return err
+			}
+			if err := enc.PackInt(int64(v3)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.PackString("Ptr"); err != nil {
+		return err
+	}
+	if x.Ptr == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else {
+		if err := (*x.Ptr).MarshalMsgPack(enc); err != nil {
+			return err
+		}
+	}
+	if err := enc.PackString("PtrInt"); err != nil {
+		return err
+	}
+	if x.PtrInt == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.PackInt(int64(*x.PtrInt)); err != nil {
+			return err
+		}
+	}
+	if err := enc.PackString("Arrays"); err != nil {
+		return err
+	}
+	if x.Arrays == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.PackArrayLen(int64(len(x.Arrays))); err != nil {
+			return err
+		}
+		for i4 := range x.Arrays {
+			if err := x.Arrays[i4].MarshalMsgPack(enc); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.PackString("List"); err != nil {
+		return err
+	}
+	if err := x.List.MarshalMsgPack(enc); err != nil {
+		return err
+	}
+	if err := enc.PackString("Nested"); err != nil {
+		return err
+	}
+	if x.Nested == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else if enc.SortMapKeys() {
+		if err := enc.Encode(x.Nested); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.PackMapLen(int64(len(x.Nested))); err != nil {
+			return err
+		}
+		for k5, v6 := range x.Nested {
+			if err := enc.PackString(k5); err != nil {
+				return err
+			}
+			if v6 == nil {
+				if err := enc.PackNil(); err != nil {
+					return err
+				}
+			} else {
+				if err := enc.PackArrayLen(int64(len(v6))); err != nil {
+					return err
+				}
+				for i7 := range v6 {
+					if v6[i7] == nil {
+						if err := enc.PackNil(); err != nil {
+							return err
+						}
+					} else {
+						if err := (*v6[i7]).MarshalMsgPack(enc); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+	}
+	if err := enc.PackString("Any"); err != nil {
+		return err
+	}
+	if x.Any == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else if enc.SortMapKeys() {
+		if err := enc.Encode(x.Any); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.PackMapLen(int64(len(x.Any))); err != nil {
+			return err
+		}
+		for k8, v9 := range x.Any {
+			if err := enc.PackString(k8); err != nil {
+				return err
+			}
+			if err := enc.Encode(v9); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.PackString("Ext"); err != nil {
+		return err
+	}
+	if x.Ext == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else if enc.SortMapKeys() {
+		if err := enc.Encode(x.Ext); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.PackMapLen(int64(len(x.Ext))); err != nil {
+			return err
+		}
+		for k10, v11 := range x.Ext {
+			if err := enc.PackInt(int64(k10)); err != nil {
+				return err
+			}
+			if err := enc.Encode(v11); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.PackString("Label"); err != nil {
+		return err
+	}
+	if err := enc.Encode(x.Label); err != nil {
+		return err
+	}
+	if err := enc.PackString("Labels"); err != nil {
+		return err
+	}
+	if err := enc.Encode(x.Labels); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.
+func (x *Containers) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	if dec.UnknownFieldsDisallowed() || dec.FieldOptions() != (msgpack.FieldOptions{}) {
+		return dec.DecodeStruct(x)
+	}
+	var errSaved error
+	saveError := func(err error) error {
+		switch err.(type) {
+		case *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+			if errSaved == nil {
+				errSaved = err
+			}
+			return nil
+		}
+		return err
+	}
+	if dec.Type() != msgpack.MapLen {
+		if err := saveError(dec.ConvertError(reflect.TypeOf(x).Elem())); err != nil {
+			return err
+		}
+		return errSaved
+	}
+	for i0, n1 := 0, dec.Len(); i0 < n1; i0++ {
+		if err := dec.Unpack(); err != nil {
+			return err
+		}
+		if t := dec.Type(); t != msgpack.String && t != msgpack.Binary {
+			if err := saveError(dec.ConvertError(reflect.TypeOf(""))); err != nil {
+				return err
+			}
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		switch string(dec.BytesNoCopy()) {
+		case "Ints":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			switch dec.Type() {
+			case msgpack.Nil:
+				x.Ints = x.Ints[:0]
+			case msgpack.ArrayLen:
+				if n3 := dec.Len(); n3 > cap(x.Ints) {
+					s4 := make([]int, n3)
+					copy(s4, x.Ints)
+					x.Ints = s4
+				} else {
+					x.Ints = x.Ints[:n3]
+				}
+				for i2 := range x.Ints {
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if v, err := dec.ConvertInt(0); err != nil {
+						if err := saveError(err); err != nil {
+							return err
+						}
+					} else {
+						x.Ints[i2] = int(v)
+					}
+				}
+			default:
+				if err := saveError(dec.ConvertError(reflect.TypeOf(x.Ints))); err != nil {
+					return err
+				}
+			}
+		case "Array":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			switch dec.Type() {
+			case msgpack.Nil, msgpack.ArrayLen:
+				n6 := 0
+				if dec.Type() == msgpack.ArrayLen {
+					n6 = dec.Len()
+				}
+				for i5 := 0; i5 < n6; i5++ {
+					if i5 >= len(x.Array) {
+						if err := dec.Unpack(); err != nil {
+							return err
+						}
+						if err := dec.Skip(); err != nil {
+							return err
+						}
+						continue
+					}
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if v, err := dec.ConvertUint(16); err != nil {
+						if err := saveError(err); err != nil {
+							return err
+						}
+					} else {
+						x.Array[i5] = uint16(v)
+					}
+				}
+				var z7 uint16
+				for i5 := n6; i5 < len(x.Array); i5++ {
+					x.Array[i5] = z7
+				}
+			default:
+				if err := saveError(dec.ConvertError(reflect.TypeOf(x.Array))); err != nil {
+					return err
+				}
+			}
+		case "Map":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if dec.Type() != msgpack.MapLen {
+				if err := saveError(dec.ConvertError(reflect.TypeOf(x.Map))); err != nil {
+					return err
+				}
+			} else {
+				if x.Map == nil {
+					x.Map = make(map[string]int)
+				}
+				for i8, n9 := 0, dec.Len(); i8 < n9; i8++ {
+					var k10 string
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if v, err := dec.ConvertString(); err != nil {
+						if err := saveError(err); err != nil {
+							return err
+						}
+					} else {
+						k10 = v
+					}
+					var v11 int
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if v, err := dec.ConvertInt(0); err != nil {
+						if err := saveError(err); err != nil {
+							return err
+						}
+					} else {
+						v11 = int(v)
+					}
+					x.Map[k10] = v11
+				}
+			}
+		case "Ptr":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if dec.Type() == msgpack.Nil {
+				x.Ptr = nil
+			} else {
+				if x.Ptr == nil {
+					x.Ptr = new(Basic)
+				}
+				if err := saveError((*x.Ptr).UnmarshalMsgPack(dec)); err != nil {
+					return err
+				}
+			}
+		case "PtrInt":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if dec.Type() == msgpack.Nil {
+				x.PtrInt = nil
+			} else {
+				if x.PtrInt == nil {
+					x.PtrInt = new(int)
+				}
+				if v, err := dec.ConvertInt(0); err != nil {
+					if err := saveError(err); err != nil {
+						return err
+					}
+				} else {
+					*x.PtrInt = int(v)
+				}
+			}
+		case "Arrays":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			switch dec.Type() {
+			case msgpack.Nil:
+				x.Arrays = x.Arrays[:0]
+			case msgpack.ArrayLen:
+				if n13 := dec.Len(); n13 > cap(x.Arrays) {
+					s14 := make([]Array, n13)
+					copy(s14, x.Arrays)
+					x.Arrays = s14
+				} else {
+					x.Arrays = x.Arrays[:n13]
+				}
+				for i12 := range x.Arrays {
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if err := saveError(x.Arrays[i12].UnmarshalMsgPack(dec)); err != nil {
+						return err
+					}
+				}
+			default:
+				if err := saveError(dec.ConvertError(reflect.TypeOf(x.Arrays))); err != nil {
+					return err
+				}
+			}
+		case "List":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := saveError(x.List.UnmarshalMsgPack(dec)); err != nil {
+				return err
+			}
+		case "Nested":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if dec.Type() != msgpack.MapLen {
+				if err := saveError(dec.ConvertError(reflect.TypeOf(x.Nested))); err != nil {
+					return err
+				}
+			} else {
+				if x.Nested == nil {
+					x.Nested = make(map[string][]*Array)
+				}
+				for i15, n16 := 0, dec.Len(); i15 < n16; i15++ {
+					var k17 string
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if v, err := dec.ConvertString(); err != nil {
+						if err := saveError(err); err != nil {
+							return err
+						}
+					} else {
+						k17 = v
+					}
+					var v18 []*Array
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					switch dec.Type() {
+					case msgpack.Nil:
+						v18 = v18[:0]
+					case msgpack.ArrayLen:
+						if n20 := dec.Len(); n20 > cap(v18) {
+							s21 := make([]*Array, n20)
+							copy(s21, v18)
+							v18 = s21
+						} else {
+							v18 = v18[:n20]
+						}
+						for i19 := range v18 {
+							if err := dec.Unpack(); err != nil {
+								return err
+							}
+							if dec.Type() == msgpack.Nil {
+								v18[i19] = nil
+							} else {
+								if v18[i19] == nil {
+									v18[i19] = new(Array)
+								}
+								if err := saveError((*v18[i19]).UnmarshalMsgPack(dec)); err != nil {
+									return err
+								}
+							}
+						}
+					default:
+						if err := saveError(dec.ConvertError(reflect.TypeOf(v18))); err != nil {
+							return err
+						}
+					}
+					x.Nested[k17] = v18
+				}
+			}
+		case "Any":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if dec.Type() != msgpack.MapLen {
+				if err := saveError(dec.ConvertError(reflect.TypeOf(x.Any))); err != nil {
+					return err
+				}
+			} else {
+				if x.Any == nil {
+					x.Any = make(map[string]interface{})
+				}
+				for i22, n23 := 0, dec.Len(); i22 < n23; i22++ {
+					var k24 string
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if v, err := dec.ConvertString(); err != nil {
+						if err := saveError(err); err != nil {
+							return err
+						}
+					} else {
+						k24 = v
+					}
+					var v25 interface{}
+					if err := saveError(dec.Decode(&v25)); err != nil {
+						return err
+					}
+					x.Any[k24] = v25
+				}
+			}
+		case "Ext":
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if dec.Type() != msgpack.MapLen {
+				if err := saveError(dec.ConvertError(reflect.TypeOf(x.Ext))); err != nil {
+					return err
+				}
+			} else {
+				if x.Ext == nil {
+					x.Ext = make(map[int]Ext)
+				}
+				for i26, n27 := 0, dec.Len(); i26 < n27; i26++ {
+					var k28 int
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if v, err := dec.ConvertInt(0); err != nil {
+						if err := saveError(err); err != nil {
+							return err
+						}
+					} else {
+						k28 = int(v)
+					}
+					var v29 Ext
+					if err := dec.Unpack(); err != nil {
+						return err
+					}
+					if err := saveError(v29.UnmarshalMsgPack(dec)); err != nil {
+						return err
+					}
+					x.Ext[k28] = v29
+				}
+			}
+		case "Label":
+			if err := saveError(dec.Decode(&x.Label)); err != nil {
+				return err
+			}
+		case "Labels":
+			if err := saveError(dec.Decode(&x.Labels)); err != nil {
+				return err
+			}
+		default:
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return errSaved
+}
+
+// MarshalMsgPack implements the msgpack.Marshaler interface.
+func (x Mode) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if err := enc.PackInt(int64(x)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.
+func (x *Mode) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	var errSaved error
+	saveError := func(err error) error {
+		switch err.(type) {
+		case *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+			if errSaved == nil {
+				errSaved = err
+			}
+			return nil
+		}
+		return err
+	}
+	if v, err := dec.ConvertInt(0); err != nil {
+		if err := saveError(err); err != nil {
+			return err
+		}
+	} else {
+		*x = Mode(v)
+	}
+	return errSaved
+}
+
+// MarshalMsgPack implements the msgpack.Marshaler interface.
+func (x List) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if x == nil {
+		if err := enc.PackNil(); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.PackArrayLen(int64(len(x))); err != nil {
+			return err
+		}
+		for i0 := range x {
+			if err := x[i0].MarshalMsgPack(enc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.
+func (x *List) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	var errSaved error
+	saveError := func(err error) error {
+		switch err.(type) {
+		case *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+			if errSaved == nil {
+				errSaved = err
+			}
+			return nil
+		}
+		return err
+	}
+	switch dec.Type() {
+	case msgpack.Nil:
+		*x = (*x)[:0]
+	case msgpack.ArrayLen:
+		if n1 := dec.Len(); n1 > cap(*x) {
+			s2 := make(List, n1)
+			copy(s2, *x)
+			*x = s2
+		} else {
+			*x = (*x)[:n1]
+		}
+		for i0 := range *x {
+			if err := dec.Unpack(); err != nil {
+				return err
+			}
+			if err := saveError((*x)[i0].UnmarshalMsgPack(dec)); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := saveError(dec.ConvertError(reflect.TypeOf(*x))); err != nil {
+			return err
+		}
+	}
+	return errSaved
+}
@@ -0,0 +1,242 @@
+package gentest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/neovim/go-client/msgpack"
+)
+
+// The plain types have the same fields as the types with generated methods.
+// The plain types are encoded and decoded with reflection.
+type (
+	plainBasic      Basic
+	plainTagged     Tagged
+	plainArray      Array
+	plainEmbed      Embed
+	plainContainers Containers
+)
+
+var (
+	one   = 1
+	basic = Basic{
+		Bool:    true,
+		Int:     -1,
+		Int8:    -2,
+		Int16:   -300,
+		Int32:   -70000,
+		Int64:   -5000000000,
+		Uint:    1,
+		Uint8:   2,
+		Uint16:  300,
+		Uint32:  70000,
+		Uint64:  5000000000,
+		Float32: 1.5,
+		Float64: 2.25,
+		String:  "hello",
+		Bytes:   []byte("world"),
+		Time:    time.Unix(1500000000, 123),
+		Mode:    3,
+		Any:     []interface{}{int64(1), "two"},
+	}
+	tagged = Tagged{
+		B: "b",
+		C: true,
+		D: &one,
+		E: []string{"e"},
+	}
+	embedRemain = Embed{
+		Meta:  Meta{Tag: "t"},
+		Extra: map[string]interface{}{"x": 1, "ID": 2},
+	}
+	containers = Containers{
+		Ints:   []int{1, 2, 3},
+		Array:  [3]uint16{4, 5, 6},
+		Map:    map[string]int{"a": 1},
+		Ptr:    &basic,
+		PtrInt: &one,
+		Arrays: []Array{{X: 1, Y: "y", Z: []int{2}}},
+		List:   List{{X: 3}},
+		Nested: map[string][]*Array{"n": {{X: 4}, nil}},
+		Any:    map[string]interface{}{"k": "v"},
+		Ext:    map[int]Ext{1: {S: "ext"}},
+		Label:  "l",
+		Labels: map[Label]int{"m": 1},
+	}
+)
+
+var encodeTests = []struct {
+	name  string
+	gen   interface{}
+	plain interface{}
+}{
+	{"Basic", basic, plainBasic(basic)},
+	{"BasicZero", Basic{}, plainBasic{}},
+	{"Tagged", tagged, plainTagged(tagged)},
+	{"TaggedEmpty", Tagged{B: "none"}, plainTagged{B: "none"}},
+	{"Array", Array{X: 1, Y: "y", Z: []int{1, 2}}, plainArray{X: 1, Y: "y", Z: []int{1, 2}}},
+	{"Embed", Embed{Base: Base{ID: 1, Name: "name"}, Ext: Ext{S: "ext"}}, plainEmbed{Base: Base{ID: 1, Name: "name"}, Ext: Ext{S: "ext"}}},
+	{"EmbedRemain", embedRemain, plainEmbed(embedRemain)},
+	{"Containers", containers, plainContainers(containers)},
+	{"ContainersZero", Containers{}, plainContainers{}},
+}
+
+func TestEncode(t *testing.T) {
+	for _, tt := range encodeTests {
+		got, err := msgpack.Marshal(tt.gen)
+		if err != nil {
+			t.Errorf("%s: Marshal(gen) returned error %v", tt.name, err)
+			continue
+		}
+		// Encode a pointer to the plain value so that fields are
+		// addressable like the fields in the generated code.
+		p := reflect.New(reflect.TypeOf(tt.plain))
+		p.Elem().Set(reflect.ValueOf(tt.plain))
+		want, err := msgpack.Marshal(p.Interface())
+		if err != nil {
+			t.Errorf("%s: Marshal(plain) returned error %v", tt.name, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s:\n got %x\nwant %x", tt.name, got, want)
+		}
+	}
+}
+
+var decodeTests = []struct {
+	name  string
+	data  string
+	gen   interface{}
+	plain interface{}
+}{
+	{"Basic", mustMarshal(plainBasic(basic)), &Basic{}, &plainBasic{}},
+	{"BasicConvert", mustMarshal(map[string]interface{}{
+		"Int8":   1000,
+		"Uint":   -1,
+		"String": 1,
+		"Bool":   "x",
+		"Mode":   2.0,
+		"Time":   nil,
+		"Bytes":  "str",
+		"Other":  []int{1, 2},
+	}), &Basic{}, &plainBasic{}},
+	{"BasicNil", mustMarshal(nil), &Basic{}, &plainBasic{}},
+	{"Tagged", mustMarshal(plainTagged(tagged)), &Tagged{}, &plainTagged{}},
+	{"TaggedEmpty", mustMarshal(map[string]interface{}{}), &Tagged{}, &plainTagged{}},
+	{"Array", mustMarshal([]interface{}{1, "y", []int{2}, "extra"}), &Array{}, &plainArray{}},
+	{"ArrayShort", mustMarshal([]interface{}{1}), &Array{}, &plainArray{}},
+	{"ArrayMap", mustMarshal(map[string]int{"X": 1}), &Array{}, &plainArray{}},
+	{"Embed", mustMarshal(map[string]interface{}{"ID": 1, "Name": "name", "ext": "ext"}), &Embed{}, &plainEmbed{}},
+	{"EmbedRemain", mustMarshal(map[string]interface{}{"ID": 1, "tag": "t", "x": []int{1}, "y": nil}), &Embed{}, &plainEmbed{}},
+	{"Containers", mustMarshal(plainContainers(containers)), &Containers{}, &plainContainers{}},
+	{"ContainersConvert", mustMarshal(map[string]interface{}{
+		"Ints":   nil,
+		"Array":  []int{1, 2, 3, 4},
+		"Map":    []int{1},
+		"Ptr":    nil,
+		"PtrInt": "x",
+		"Nested": map[string]interface{}{"n": []interface{}{nil, map[string]int{}}},
+	}), &Containers{}, &plainContainers{}},
+	{"ContainersShortArray", mustMarshal(map[string]interface{}{"Array": []int{1}}), &Containers{Array: [3]uint16{7, 8, 9}}, &plainContainers{Array: [3]uint16{7, 8, 9}}},
+}
+
+func mustMarshal(v interface{}) string {
+	p, err := msgpack.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(p)
+}
+
+func TestDecode(t *testing.T) {
+	for _, tt := range decodeTests {
+		errGen := msgpack.Unmarshal([]byte(tt.data), tt.gen)
+		errPlain := msgpack.Unmarshal([]byte(tt.data), tt.plain)
+		if _, ok := errPlain.(*msgpack.DecodeConvertError); errPlain != nil && !ok {
+			t.Errorf("%s: Unmarshal(plain) returned error %v", tt.name, errPlain)
+			continue
+		}
+		if (errGen == nil) != (errPlain == nil) || reflect.TypeOf(errGen) != reflect.TypeOf(errPlain) {
+			t.Errorf("%s: Unmarshal(gen) returned error %v, want %v", tt.name, errGen, errPlain)
+		}
+		got := reflect.ValueOf(tt.gen).Elem().Convert(reflect.TypeOf(tt.plain).Elem()).Interface()
+		want := reflect.ValueOf(tt.plain).Elem().Interface()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: data %s\n got %#v\nwant %#v", tt.name, hex.EncodeToString([]byte(tt.data)), got, want)
+		}
+	}
+}
+
+var decodeOptionsTests = []struct {
+	name    string
+	data    string
+	gen     interface{}
+	plain   interface{}
+	options func(*msgpack.Decoder)
+}{
+	{"Unknown", mustMarshal(map[string]interface{}{"Int": 1, "Other": 2, "String": "s"}), &Basic{}, &plainBasic{},
+		(*msgpack.Decoder).DisallowUnknownFields},
+	{"UnknownNested", mustMarshal(map[string]interface{}{"Ptr": map[string]interface{}{"Int": 1, "Other": 2}, "Label": "l"}), &Containers{}, &plainContainers{},
+		(*msgpack.Decoder).DisallowUnknownFields},
+	{"UnknownTagged", mustMarshal(map[string]interface{}{"a": 1, "A": 2}), &Tagged{}, &plainTagged{},
+		(*msgpack.Decoder).DisallowUnknownFields},
+	{"UnknownRemain", mustMarshal(map[string]interface{}{"ID": 1, "x": 2}), &Embed{}, &plainEmbed{},
+		(*msgpack.Decoder).DisallowUnknownFields},
+	{"UnknownArray", mustMarshal([]interface{}{1, "y", []int{2}, "extra"}), &Array{}, &plainArray{},
+		(*msgpack.Decoder).DisallowUnknownFields},
+	{"CaseInsensitive", mustMarshal(map[string]interface{}{"int": 1, "STRING": "s", "b": "b"}), &Basic{}, &plainBasic{},
+		func(d *msgpack.Decoder) { d.SetFieldOptions(msgpack.FieldOptions{CaseInsensitive: true}) }},
+	{"SnakeCase", mustMarshal(map[string]interface{}{"int": 1, "Int": 2, "float64": 1.5}), &Basic{}, &plainBasic{},
+		func(d *msgpack.Decoder) { d.SetFieldOptions(msgpack.FieldOptions{Naming: msgpack.SnakeCaseFieldNames}) }},
+}
+
+func TestDecodeOptions(t *testing.T) {
+	decode := func(data string, v interface{}, options func(*msgpack.Decoder)) error {
+		dec := msgpack.NewDecoder(bytes.NewReader([]byte(data)))
+		options(dec)
+		return dec.Decode(v)
+	}
+	for _, tt := range decodeOptionsTests {
+		errGen := decode(tt.data, tt.gen, tt.options)
+		errPlain := decode(tt.data, tt.plain, tt.options)
+		switch errPlain.(type) {
+		case nil, *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+		default:
+			t.Errorf("%s: Decode(plain) returned error %v", tt.name, errPlain)
+			continue
+		}
+		if (errGen == nil) != (errPlain == nil) || reflect.TypeOf(errGen) != reflect.TypeOf(errPlain) {
+			t.Errorf("%s: Decode(gen) returned error %v, want %v", tt.name, errGen, errPlain)
+		}
+		got := reflect.ValueOf(tt.gen).Elem().Convert(reflect.TypeOf(tt.plain).Elem()).Interface()
+		want := reflect.ValueOf(tt.plain).Elem().Interface()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: data %s\n got %#v\nwant %#v", tt.name, hex.EncodeToString([]byte(tt.data)), got, want)
+		}
+	}
+}
+
+func TestEncodeSortMapKeys(t *testing.T) {
+	gen := Containers{Map: map[string]int{"c": 3, "a": 1, "b": 2}}
+	plain := plainContainers(gen)
+	genEmbed := Embed{Extra: map[string]interface{}{"c": 3, "a": 1, "b": 2}}
+	plainEmbed := plainEmbed(genEmbed)
+	encode := func(v interface{}) string {
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetSortMapKeys(true)
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+		return hex.EncodeToString(buf.Bytes())
+	}
+	if got, want := encode(gen), encode(&plain); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := encode(genEmbed), encode(&plainEmbed); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,1259 @@
+// Command msgpackgen generates MarshalMsgPack and UnmarshalMsgPack methods
+// for Go types. The generated methods implement the msgpack.Marshaler and
+// msgpack.Unmarshaler interfaces without the reflection used by
+// msgpack.Encoder.Encode and msgpack.Decoder.Decode.
+//
+// Usage:
+//
+//	msgpackgen -type T[,T...] [-output file] [directory]
+//
+// Msgpackgen parses the Go package in directory (default ".") and writes the
+// methods for the named types to file (default <type>_msgpack.go in
+// directory, where <type> is the lower case name of the first type).
+//
+// The generated methods encode and decode the same MessagePack values as
+// Encode and Decode. The struct field tags "array", "omitempty", "inline",
+// "remain" and "empty" have the same meaning as documented for Encode. Inline
+// fields must have a struct type declared in the package.
+//
+// Msgpackgen resolves types from source without type checking. Values of
+// types that msgpackgen does not resolve, such as interfaces, structs without
+// generated methods, types with MarshalBinary or MarshalText methods and types
+// from other packages except time.Time, are encoded and decoded with Encode
+// and Decode. Maps with keys from other packages or keys with a MarshalText
+// method are also encoded and decoded with Encode and Decode, and maps are
+// encoded with Encode when the encoder sorts map keys. Extensions registered
+// with msgpack.RegisterExtension apply only to the values encoded and decoded
+// with Encode and Decode. The generated UnmarshalMsgPack methods for structs
+// decode with Decoder.DecodeStruct when DisallowUnknownFields or
+// SetFieldOptions was called on the decoder. The generated MarshalMsgPack
+// methods ignore the field options of the encoder.
+//
+// Msgpackgen is typically run with a go:generate directive:
+//
+//	//go:generate msgpackgen -type Point,Line
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of type names; must be set")
+	output    = flag.String("output", "", "output file name; default srcdir/<type>_msgpack.go")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of msgpackgen:\n")
+	fmt.Fprintf(os.Stderr, "\tmsgpackgen -type T[,T...] [-output file] [directory]\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("msgpackgen: ")
+	flag.Usage = usage
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	dir := "."
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		dir = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	outputName := *output
+	if outputName == "" {
+		outputName = filepath.Join(dir, strings.ToLower(types[0])+"_msgpack.go")
+	}
+
+	command := strings.Join(append([]string{"msgpackgen"}, os.Args[1:]...), " ")
+	src, err := generate(dir, types, filepath.Base(outputName), command)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(outputName, src, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate returns the source for the methods of the named types in the
+// package in dir. The file named exclude, the previous output, is not parsed.
+func generate(dir string, typeNames []string, exclude string, command string) ([]byte, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		fset:    token.NewFileSet(),
+		types:   make(map[string]*typeDecl),
+		methods: make(map[string]map[string]recv),
+		gen:     make(map[string]bool),
+		imports: map[string]string{"msgpack": msgpackPath},
+	}
+
+	for _, name := range pkg.GoFiles {
+		if name == exclude {
+			continue
+		}
+		file, err := parser.ParseFile(g.fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		g.addFile(file)
+	}
+
+	for _, name := range typeNames {
+		if g.types[name] == nil {
+			return nil, fmt.Errorf("type %s not found in package %s", name, pkg.Name)
+		}
+		g.gen[name] = true
+	}
+
+	for _, name := range typeNames {
+		if err := g.generateType(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by \"%s\"; DO NOT EDIT.\n\n", command)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg.Name)
+	g.writeImports(&buf)
+	buf.Write(g.buf.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+const msgpackPath = "github.com/neovim/go-client/msgpack"
+
+// recv is the receiver kind of a method.
+type recv int
+
+const (
+	noRecv recv = iota
+	valueRecv
+	ptrRecv
+)
+
+type typeDecl struct {
+	spec *ast.TypeSpec
+	file *ast.File
+}
+
+type generator struct {
+	fset *token.FileSet
+
+	// types is the type declarations in the package.
+	types map[string]*typeDecl
+
+	// methods is the receiver kind of the methods declared on the types in
+	// the package.
+	methods map[string]map[string]recv
+
+	// gen is the set of types with generated methods.
+	gen map[string]bool
+
+	// imports maps the package names used in the generated code to the
+	// package import paths.
+	imports map[string]string
+
+	// resolving is the set of types currently being resolved.
+	resolving map[string]bool
+
+	// nvar is the number of local variables in the current function.
+	nvar int
+
+	buf bytes.Buffer
+}
+
+func (g *generator) addFile(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				g.types[spec.Name.Name] = &typeDecl{spec: spec, file: file}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) != 1 {
+				continue
+			}
+			r := valueRecv
+			typ := decl.Recv.List[0].Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				r = ptrRecv
+				typ = star.X
+			}
+			ident, ok := typ.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			m := g.methods[ident.Name]
+			if m == nil {
+				m = make(map[string]recv)
+				g.methods[ident.Name] = m
+			}
+			m[decl.Name.Name] = r
+		}
+	}
+}
+
+func (g *generator) writeImports(buf *bytes.Buffer) {
+	var std, other []string
+	for name, p := range g.imports {
+		spec := strconv.Quote(p)
+		if name != path.Base(p) {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.SplitN(p, "/", 2)[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	buf.WriteString("import (\n")
+	for _, spec := range std {
+		buf.WriteString(spec + "\n")
+	}
+	if len(std) > 0 && len(other) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, spec := range other {
+		buf.WriteString(spec + "\n")
+	}
+	buf.WriteString(")\n\n")
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// use records the import of package name for the generated code.
+func (g *generator) use(name, path string) error {
+	if p, ok := g.imports[name]; ok && p != path {
+		return fmt.Errorf("package name %s used for both %s and %s", name, p, path)
+	}
+	g.imports[name] = path
+	return nil
+}
+
+// typeStr returns the Go source for t and records the imports used by the
+// source.
+func (g *generator) typeStr(t *typeInfo) string {
+	for name, path := range t.imports {
+		if err := g.use(name, path); err != nil {
+			panic(generateError{err})
+		}
+	}
+	return t.str
+}
+
+// generateError is used to abort generation from deep in the code
+// generator.
+type generateError struct{ err error }
+
+// kind is the kind of encoding and decoding used for a type.
+type kind int
+
+const (
+	// fallbackKind types are encoded and decoded by Encode and Decode.
+	fallbackKind kind = iota
+	boolKind
+	intKind
+	uintKind
+	floatKind
+	stringKind
+	bytesKind
+	timeKind
+	ptrKind
+	sliceKind
+	arrayKind
+	mapKind
+)
+
+// typeInfo describes how to encode and decode a type.
+type typeInfo struct {
+	kind kind
+
+	// str is the type as written in Go source.
+	str string
+
+	// imports maps package names used in str to import paths.
+	imports map[string]string
+
+	// basic is the predeclared type with the same underlying type for
+	// boolKind, intKind, uintKind, floatKind and stringKind.
+	basic string
+
+	// bits is the bit size of numeric types. The bit size of int and uint
+	// is zero.
+	bits int
+
+	// key and elem are the key and element types of maps, slices, arrays
+	// and pointers.
+	key, elem *typeInfo
+
+	// marshal and unmarshal are the receiver kinds of the MarshalMsgPack and
+	// UnmarshalMsgPack methods.
+	marshal, unmarshal recv
+
+	// nonEmpty is a format for the Go expression that tests if a value is
+	// not empty for the "omitempty" option. The value is never empty if
+	// nonEmpty is "".
+	nonEmpty string
+
+	// unknown is set for types from other packages where msgpackgen cannot
+	// determine nonEmpty.
+	unknown bool
+
+	// text is set for types with a MarshalText method.
+	text bool
+}
+
+var basicTypes = map[string]*typeInfo{
+	"bool":    {kind: boolKind, basic: "bool", nonEmpty: "%s"},
+	"int":     {kind: intKind, basic: "int", nonEmpty: "%s != 0"},
+	"int8":    {kind: intKind, basic: "int8", bits: 8, nonEmpty: "%s != 0"},
+	"int16":   {kind: intKind, basic: "int16", bits: 16, nonEmpty: "%s != 0"},
+	"int32":   {kind: intKind, basic: "int32", bits: 32, nonEmpty: "%s != 0"},
+	"rune":    {kind: intKind, basic: "rune", bits: 32, nonEmpty: "%s != 0"},
+	"int64":   {kind: intKind, basic: "int64", bits: 64, nonEmpty: "%s != 0"},
+	"uint":    {kind: uintKind, basic: "uint", nonEmpty: "%s != 0"},
+	"uint8":   {kind: uintKind, basic: "uint8", bits: 8, nonEmpty: "%s != 0"},
+	"byte":    {kind: uintKind, basic: "byte", bits: 8, nonEmpty: "%s != 0"},
+	"uint16":  {kind: uintKind, basic: "uint16", bits: 16, nonEmpty: "%s != 0"},
+	"uint32":  {kind: uintKind, basic: "uint32", bits: 32, nonEmpty: "%s != 0"},
+	"uint64":  {kind: uintKind, basic: "uint64", bits: 64, nonEmpty: "%s != 0"},
+	"uintptr": {kind: uintKind, basic: "uintptr", bits: 64, nonEmpty: "%s != 0"},
+	"float32": {kind: floatKind, basic: "float32", bits: 32, nonEmpty: "%s != 0"},
+	"float64": {kind: floatKind, basic: "float64", bits: 64, nonEmpty: "%s != 0"},
+	"string":  {kind: stringKind, basic: "string", nonEmpty: "len(%s) != 0"},
+	"error":   {kind: fallbackKind, nonEmpty: "%s != nil"},
+}
+
+// resolve returns the typeInfo for the type expression expr in file.
+func (g *generator) resolve(expr ast.Expr, file *ast.File) *typeInfo {
+	t := g.resolveUnderlying(expr, file)
+	t.str = g.exprStr(expr)
+	t.imports = g.fileImports(expr, file)
+	return t
+}
+
+func (g *generator) resolveUnderlying(expr ast.Expr, file *ast.File) *typeInfo {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return g.resolveUnderlying(expr.X, file)
+	case *ast.Ident:
+		if g.types[expr.Name] != nil {
+			return g.resolveLocal(expr.Name)
+		}
+		if t, ok := basicTypes[expr.Name]; ok {
+			t := *t
+			return &t
+		}
+		// Unknown identifiers, such as type parameters and any, are decoded
+		// with reflection.
+		return &typeInfo{nonEmpty: "%s != nil"}
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok && expr.Sel.Name == "Time" && g.fileImports(expr, file)[x.Name] == "time" {
+			return &typeInfo{kind: timeKind}
+		}
+		return &typeInfo{unknown: true}
+	case *ast.StarExpr:
+		elem := g.resolve(expr.X, file)
+		if elem.kind == fallbackKind && elem.unmarshal == noRecv {
+			return &typeInfo{nonEmpty: "%s != nil"}
+		}
+		return &typeInfo{kind: ptrKind, elem: elem, nonEmpty: "%s != nil"}
+	case *ast.ArrayType:
+		elem := g.resolve(expr.Elt, file)
+		if expr.Len != nil {
+			return &typeInfo{kind: arrayKind, elem: elem, nonEmpty: "len(%s) != 0"}
+		}
+		if elem.kind == uintKind && elem.bits == 8 {
+			if elem.str == "byte" || elem.str == "uint8" {
+				return &typeInfo{kind: bytesKind, nonEmpty: "len(%s) != 0"}
+			}
+			// Encode and Decode handle slices of named byte types as
+			// binary, but the generated code cannot convert these slices
+			// to []byte.
+			return &typeInfo{nonEmpty: "len(%s) != 0"}
+		}
+		return &typeInfo{kind: sliceKind, elem: elem, nonEmpty: "len(%s) != 0"}
+	case *ast.MapType:
+		key := g.resolve(expr.Key, file)
+		elem := g.resolve(expr.Value, file)
+		if key.text || key.unknown {
+			// Encode and Decode handle map keys with a MarshalText
+			// method as strings.
+			return &typeInfo{nonEmpty: "len(%s) != 0"}
+		}
+		return &typeInfo{kind: mapKind, key: key, elem: elem, nonEmpty: "len(%s) != 0"}
+	case *ast.InterfaceType:
+		return &typeInfo{nonEmpty: "%s != nil"}
+	default:
+		// Structs, functions and channels.
+		return &typeInfo{}
+	}
+}
+
+func (g *generator) resolveLocal(name string) *typeInfo {
+	d := g.types[name]
+	if g.resolving[name] {
+		return &typeInfo{unknown: true}
+	}
+	if g.resolving == nil {
+		g.resolving = make(map[string]bool)
+	}
+	g.resolving[name] = true
+	defer delete(g.resolving, name)
+
+	t := g.resolve(d.spec.Type, d.file)
+	if d.spec.Assign.IsValid() {
+		// Type alias.
+		return t
+	}
+
+	if t.kind == timeKind {
+		// Encode and Decode handle types defined as time.Time as structs.
+		t.kind = fallbackKind
+		t.nonEmpty = ""
+	}
+
+	m := g.methods[name]
+	if g.gen[name] {
+		t.marshal = valueRecv
+		t.unmarshal = ptrRecv
+	} else {
+		t.marshal = m["MarshalMsgPack"]
+		t.unmarshal = m["UnmarshalMsgPack"]
+		if t.marshal == noRecv && t.unmarshal == noRecv {
+			for _, name := range []string{"MarshalBinary", "MarshalText", "UnmarshalBinary", "UnmarshalText"} {
+				if m[name] != noRecv {
+					// Encode and Decode use the encoding package
+					// interfaces.
+					t.kind = fallbackKind
+				}
+			}
+		}
+		t.text = m["MarshalText"] == valueRecv
+	}
+	return t
+}
+
+// exprStr returns the Go source for expr.
+func (g *generator) exprStr(expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.fset, expr)
+	return buf.String()
+}
+
+// fileImports returns the packages from file used in expr.
+func (g *generator) fileImports(expr ast.Expr, file *ast.File) map[string]string {
+	var imports map[string]string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		for _, spec := range file.Imports {
+			p, _ := strconv.Unquote(spec.Path.Value)
+			name := path.Base(p)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			if name == x.Name {
+				if imports == nil {
+					imports = make(map[string]string)
+				}
+				imports[name] = p
+			}
+		}
+		return true
+	})
+	return imports
+}
+
+// field is a struct field.
+type field struct {
+	// name is the MessagePack map key.
+	name string
+	// sel is the selector for the field in the Go struct.
+	sel       string
+	omitEmpty bool
+	array     bool
+	remain    bool
+	// empty is the Go source for the "empty" tag value.
+	empty string
+	typ   *typeInfo
+}
+
+// collectFields collects the fields of st following the rules used by Encode
+// and Decode.
+func (g *generator) collectFields(fields []*field, typeName string, st *ast.StructType, file *ast.File, visited map[string]bool, depth map[string]int, sel []string) ([]*field, error) {
+	// Break recursion.
+	if visited[typeName] {
+		return fields, nil
+	}
+	visited[typeName] = true
+
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+
+		var (
+			name      string
+			omitEmpty bool
+			array     bool
+			inline    bool
+			remain    bool
+		)
+		for i, p := range strings.Split(tag.Get("msgpack"), ",") {
+			if i == 0 {
+				name = p
+			} else if p == "omitempty" {
+				omitEmpty = true
+			} else if p == "array" {
+				array = true
+			} else if p == "inline" {
+				inline = true
+			} else if p == "remain" {
+				remain = true
+			} else {
+				return nil, fmt.Errorf("unknown field tag %s for type %s", p, typeName)
+			}
+		}
+
+		if name == "-" {
+			// Skip field when field tag starts with "-".
+			continue
+		}
+
+		goNames := make([]string, len(f.Names))
+		for i, ident := range f.Names {
+			goNames[i] = ident.Name
+		}
+		if len(f.Names) == 0 {
+			// Anonymous field.
+			typ := f.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			var embedded string
+			switch typ := typ.(type) {
+			case *ast.Ident:
+				embedded = typ.Name
+			case *ast.SelectorExpr:
+				embedded = typ.Sel.Name
+			default:
+				return nil, fmt.Errorf("unsupported anonymous field in type %s", typeName)
+			}
+			if name == "" || inline {
+				d := g.types[embedded]
+				if _, isIdent := typ.(*ast.Ident); isIdent && d != nil {
+					if est, ok := d.spec.Type.(*ast.StructType); ok {
+						if typ != f.Type {
+							return nil, fmt.Errorf("embedded pointer field %s in type %s not supported", embedded, typeName)
+						}
+						// Flatten anonymous struct field.
+						var err error
+						fields, err = g.collectFields(fields, embedded, est, d.file, visited, depth, append(sel, embedded))
+						if err != nil {
+							return nil, err
+						}
+						continue
+					}
+				} else if _, isSel := typ.(*ast.SelectorExpr); isSel {
+					return nil, fmt.Errorf("cannot determine fields of embedded field %s in type %s", g.exprStr(typ), typeName)
+				}
+			}
+			if inline {
+				return nil, fmt.Errorf("inline field %s in type %s is not a struct", embedded, typeName)
+			}
+			if !ast.IsExported(embedded) {
+				continue
+			}
+			goNames = []string{embedded}
+		}
+
+		for _, goName := range goNames {
+			if !ast.IsExported(goName) {
+				// Skip field if not exported.
+				continue
+			}
+			if inline {
+				// Flatten inline struct field.
+				var d *typeDecl
+				if ident, ok := f.Type.(*ast.Ident); ok {
+					d = g.types[ident.Name]
+				}
+				var est *ast.StructType
+				if d != nil {
+					est, _ = d.spec.Type.(*ast.StructType)
+				}
+				if est == nil {
+					return nil, fmt.Errorf("inline field %s.%s does not have a struct type from the package", typeName, goName)
+				}
+				var err error
+				fields, err = g.collectFields(fields, d.spec.Name.Name, est, d.file, visited, depth, append(sel, goName))
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			if remain {
+				fd := &field{
+					name:   goName,
+					sel:    strings.Join(append(append([]string(nil), sel...), goName), "."),
+					remain: true,
+					typ:    g.resolve(f.Type, file),
+				}
+				if fd.typ.kind != mapKind || fd.typ.key.kind != stringKind {
+					return nil, fmt.Errorf("remain field %s.%s is not a map with string keys", typeName, goName)
+				}
+				fields = append(fields, fd)
+				continue
+			}
+
+			name := name
+			if name == "" {
+				name = goName
+			}
+
+			// Check for name collisions.
+			d, found := depth[name]
+			if !found {
+				d = 65535
+			}
+			if len(sel) == d {
+				// There is another field with same name and same depth.
+				// Remove that field and skip this field.
+				j := 0
+				for i := 0; i < len(fields); i++ {
+					if name != fields[i].name {
+						fields[j] = fields[i]
+						j++
+					}
+				}
+				fields = fields[:j]
+				continue
+			}
+			depth[name] = len(sel)
+
+			fd := &field{
+				name:      name,
+				sel:       strings.Join(append(append([]string(nil), sel...), goName), "."),
+				omitEmpty: omitEmpty,
+				array:     array,
+				typ:       g.resolve(f.Type, file),
+			}
+
+			// Parse empty field tag.
+			if e := tag.Get("empty"); e != "" {
+				var err error
+				fd.empty, err = emptyLiteral(e, fd.typ)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing field empty field %s.%s: %v", typeName, goName, err)
+				}
+			}
+
+			if fd.omitEmpty && fd.empty == "" && fd.typ.unknown {
+				return nil, fmt.Errorf("cannot determine empty value of field %s.%s", typeName, goName)
+			}
+
+			fields = append(fields, fd)
+		}
+	}
+	return fields, nil
+}
+
+// emptyLiteral returns the Go source for the value of an "empty" tag.
+func emptyLiteral(e string, t *typeInfo) (string, error) {
+	switch {
+	case t.kind == intKind:
+		bits := t.bits
+		if _, err := strconv.ParseInt(e, 10, bits); err != nil {
+			return "", err
+		}
+		return e, nil
+	case t.kind == boolKind:
+		v, err := strconv.ParseBool(e)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(v), nil
+	case t.kind == stringKind:
+		return strconv.Quote(e), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", t.str)
+	}
+}
+
+func (g *generator) generateType(name string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			ge, ok := r.(generateError)
+			if !ok {
+				panic(r)
+			}
+			err = ge.err
+		}
+	}()
+
+	d := g.types[name]
+	if d.spec.Assign.IsValid() {
+		return fmt.Errorf("cannot generate methods for type alias %s", name)
+	}
+
+	if st, ok := d.spec.Type.(*ast.StructType); ok {
+		fields, err := g.collectFields(nil, name, st, d.file, make(map[string]bool), make(map[string]int), nil)
+		if err != nil {
+			return err
+		}
+		var remain *field
+		array := false
+		j := 0
+		for _, f := range fields {
+			if f.remain {
+				if remain != nil {
+					return fmt.Errorf("type %s has more than one remain field", name)
+				}
+				remain = f
+				continue
+			}
+			if f.array {
+				array = true
+			}
+			fields[j] = f
+			j++
+		}
+		fields = fields[:j]
+		if array && remain != nil {
+			return fmt.Errorf("array type %s has remain field", name)
+		}
+		if array {
+			g.structArrayMarshal(name, fields)
+			g.structArrayUnmarshal(name, fields)
+		} else {
+			g.structMarshal(name, fields, remain)
+			g.structUnmarshal(name, fields, remain)
+		}
+		return nil
+	}
+
+	g.resolving = map[string]bool{name: true}
+	t := g.resolve(d.spec.Type, d.file)
+	g.resolving = nil
+	t.str, t.imports = name, nil
+	// The methods of the underlying type are not methods of the type.
+	t.marshal, t.unmarshal = noRecv, noRecv
+	switch t.kind {
+	case fallbackKind, timeKind, ptrKind:
+		return fmt.Errorf("cannot generate methods for type %s", name)
+	}
+
+	g.nvar = 0
+	g.printf("// MarshalMsgPack implements the msgpack.Marshaler interface.\n")
+	g.printf("func (x %s) MarshalMsgPack(enc *msgpack.Encoder) error {\n", name)
+	g.encode("x", t, true)
+	g.printf("return nil\n")
+	g.printf("}\n\n")
+
+	g.nvar = 0
+	g.printf("// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.\n")
+	g.printf("func (x *%s) UnmarshalMsgPack(dec *msgpack.Decoder) error {\n", name)
+	g.saveErrorFunc()
+	g.decodeCurrent("*x", t)
+	g.printf("return errSaved\n")
+	g.printf("}\n\n")
+	return nil
+}
+
+func (g *generator) structMarshal(name string, fields []*field, remain *field) {
+	g.nvar = 0
+	g.printf("// MarshalMsgPack implements the msgpack.Marshaler interface.\n")
+	g.printf("func (x %s) MarshalMsgPack(enc *msgpack.Encoder) error {\n", name)
+
+	var rest string
+	if remain != nil {
+		// Collect the keys of the remain map that are not field names.
+		rest = g.newVar("rest")
+		k := g.newVar("k")
+		g.printf("var %s []%s\n", rest, g.typeStr(remain.typ.key))
+		g.printf("for %s := range x.%s {\n", k, remain.sel)
+		if len(fields) > 0 {
+			names := make([]string, len(fields))
+			for i, f := range fields {
+				names[i] = strconv.Quote(f.name)
+			}
+			g.printf("switch %s {\n", k)
+			g.printf("case %s:\n", strings.Join(names, ", "))
+			g.printf("continue\n")
+			g.printf("}\n")
+		}
+		g.printf("%s = append(%s, %s)\n", rest, rest, k)
+		g.printf("}\n")
+		if err := g.use("sort", "sort"); err != nil {
+			panic(generateError{err})
+		}
+		g.printf("if enc.SortMapKeys() {\n")
+		g.printf("sort.Slice(%s, func(i, j int) bool { return %s[i] < %s[j] })\n", rest, rest, rest)
+		g.printf("}\n")
+	}
+
+	n := 0
+	omitEmpty := false
+	for _, f := range fields {
+		if f.omitEmpty && f.nonEmpty("x."+f.sel) != "" {
+			omitEmpty = true
+		} else {
+			n++
+		}
+	}
+	if remain != nil {
+		g.printf("n := int64(%d + len(%s))\n", n, rest)
+		for _, f := range fields {
+			if cond := f.nonEmpty("x." + f.sel); f.omitEmpty && cond != "" {
+				g.printf("if %s {\nn++\n}\n", cond)
+			}
+		}
+		g.check("enc.PackMapLen(n)")
+	} else if omitEmpty {
+		g.printf("n := int64(%d)\n", n)
+		for _, f := range fields {
+			if cond := f.nonEmpty("x." + f.sel); f.omitEmpty && cond != "" {
+				g.printf("if %s {\nn++\n}\n", cond)
+			}
+		}
+		g.check("enc.PackMapLen(n)")
+	} else {
+		g.check("enc.PackMapLen(%d)", n)
+	}
+
+	for _, f := range fields {
+		cond := f.nonEmpty("x." + f.sel)
+		if f.omitEmpty && cond != "" {
+			g.printf("if %s {\n", cond)
+		}
+		g.check("enc.PackString(%s)", strconv.Quote(f.name))
+		g.encode("x."+f.sel, f.typ, true)
+		if f.omitEmpty && cond != "" {
+			g.printf("}\n")
+		}
+	}
+	if remain != nil {
+		k := g.newVar("k")
+		g.printf("for _, %s := range %s {\n", k, rest)
+		if g.typeStr(remain.typ.key) == "string" {
+			g.check("enc.PackString(%s)", k)
+		} else {
+			g.check("enc.PackString(string(%s))", k)
+		}
+		g.encode("x."+remain.sel+"["+k+"]", remain.typ.elem, false)
+		g.printf("}\n")
+	}
+	g.printf("return nil\n")
+	g.printf("}\n\n")
+}
+
+func (g *generator) structArrayMarshal(name string, fields []*field) {
+	g.nvar = 0
+	g.printf("// MarshalMsgPack implements the msgpack.Marshaler interface.\n")
+	g.printf("func (x %s) MarshalMsgPack(enc *msgpack.Encoder) error {\n", name)
+	g.check("enc.PackArrayLen(%d)", len(fields))
+	for _, f := range fields {
+		g.encode("x."+f.sel, f.typ, true)
+	}
+	g.printf("return nil\n")
+	g.printf("}\n\n")
+}
+
+func (g *generator) structUnmarshal(name string, fields []*field, remain *field) {
+	g.nvar = 0
+	g.printf("// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.\n")
+	g.printf("func (x *%s) UnmarshalMsgPack(dec *msgpack.Decoder) error {\n", name)
+	g.decodeStructFallback()
+	g.saveErrorFunc()
+	for _, f := range fields {
+		if f.empty != "" {
+			g.printf("x.%s = %s\n", f.sel, f.empty)
+		}
+	}
+	g.printf("if dec.Type() != msgpack.MapLen {\n")
+	g.convertError("reflect.TypeOf(x).Elem()")
+	g.printf("return errSaved\n")
+	g.printf("}\n")
+
+	i, n := g.newVar("i"), g.newVar("n")
+	g.printf("for %s, %s := 0, dec.Len(); %s < %s; %s++ {\n", i, n, i, n, i)
+	g.unpack()
+	g.printf("if t := dec.Type(); t != msgpack.String && t != msgpack.Binary {\n")
+	g.convertError(`reflect.TypeOf("")`)
+	g.unpack()
+	g.skip()
+	g.printf("continue\n")
+	g.printf("}\n")
+	g.printf("switch string(dec.BytesNoCopy()) {\n")
+	for _, f := range fields {
+		g.printf("case %s:\n", strconv.Quote(f.name))
+		g.decodeNext("x."+f.sel, f.typ)
+	}
+	g.printf("default:\n")
+	if remain != nil {
+		k, v := g.newVar("k"), g.newVar("v")
+		x := "x." + remain.sel
+		g.printf("if %s == nil {\n%s = make(%s)\n}\n", x, x, g.typeStr(remain.typ))
+		if key := g.typeStr(remain.typ.key); key == "string" {
+			g.printf("%s := dec.String()\n", k)
+		} else {
+			g.printf("%s := %s(dec.String())\n", k, key)
+		}
+		g.printf("var %s %s\n", v, g.typeStr(remain.typ.elem))
+		g.decodeNext(v, remain.typ.elem)
+		g.printf("%s[%s] = %s\n", x, k, v)
+	} else {
+		g.unpack()
+		g.skip()
+	}
+	g.printf("}\n")
+	g.printf("}\n")
+	g.printf("return errSaved\n")
+	g.printf("}\n\n")
+}
+
+func (g *generator) structArrayUnmarshal(name string, fields []*field) {
+	g.nvar = 0
+	g.printf("// UnmarshalMsgPack implements the msgpack.Unmarshaler interface.\n")
+	g.printf("func (x *%s) UnmarshalMsgPack(dec *msgpack.Decoder) error {\n", name)
+	g.decodeStructFallback()
+	g.saveErrorFunc()
+	g.printf("if dec.Type() != msgpack.ArrayLen {\n")
+	g.convertError("reflect.TypeOf(x).Elem()")
+	g.printf("return errSaved\n")
+	g.printf("}\n")
+
+	i, n := g.newVar("i"), g.newVar("n")
+	g.printf("for %s, %s := 0, dec.Len(); %s < %s; %s++ {\n", i, n, i, n, i)
+	g.printf("switch %s {\n", i)
+	for j, f := range fields {
+		g.printf("case %d:\n", j)
+		g.decodeNext("x."+f.sel, f.typ)
+	}
+	g.printf("default:\n")
+	g.unpack()
+	g.skip()
+	g.printf("}\n")
+	g.printf("}\n")
+	g.printf("return errSaved\n")
+	g.printf("}\n\n")
+}
+
+// decodeStructFallback writes the code to decode a struct with
+// Decoder.DecodeStruct when the decoder has options that the generated code
+// does not implement.
+func (g *generator) decodeStructFallback() {
+	g.printf("if dec.UnknownFieldsDisallowed() || dec.FieldOptions() != (msgpack.FieldOptions{}) {\n")
+	g.printf("return dec.DecodeStruct(x)\n")
+	g.printf("}\n")
+}
+
+// nonEmpty returns the Go expression that tests if the field value x is not
+// empty or "" if the field value is never empty.
+func (f *field) nonEmpty(x string) string {
+	if f.empty != "" {
+		return x + " != " + f.empty
+	}
+	if f.typ.nonEmpty == "" {
+		return ""
+	}
+	return fmt.Sprintf(f.typ.nonEmpty, x)
+}
+
+// operand returns x as an operand for a selector, index or slice
+// expression.
+func operand(x string) string {
+	if strings.HasPrefix(x, "*") {
+		return "(" + x + ")"
+	}
+	return x
+}
+
+func (g *generator) newVar(prefix string) string {
+	v := prefix + strconv.Itoa(g.nvar)
+	g.nvar++
+	return v
+}
+
+// check writes a call to a function that returns an error.
+func (g *generator) check(format string, args ...interface{}) {
+	g.printf("if err := %s; err != nil {\nreturn err\n}\n", fmt.Sprintf(format, args...))
+}
+
+// saveErrorFunc writes the declaration of the saveError function used by
+// the generated code to continue decoding after conversion errors.
+func (g *generator) saveErrorFunc() {
+	g.printf(`var errSaved error
+saveError := func(err error) error {
+	switch err.(type) {
+	case *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+		if errSaved == nil {
+			errSaved = err
+		}
+		return nil
+	}
+	return err
+}
+`)
+}
+
+func (g *generator) save(format string, args ...interface{}) {
+	g.check("saveError(%s)", fmt.Sprintf(format, args...))
+}
+
+func (g *generator) convertError(destType string) {
+	if err := g.use("reflect", "reflect"); err != nil {
+		panic(generateError{err})
+	}
+	g.save("dec.ConvertError(%s)", destType)
+}
+
+func (g *generator) unpack() { g.check("dec.Unpack()") }
+func (g *generator) skip()   { g.check("dec.Skip()") }
+
+// conv returns the Go source for converting x from type from to type t.
+func (g *generator) conv(t *typeInfo, from string, x string) string {
+	if t.str == from {
+		return x
+	}
+	return g.typeStr(&typeInfo{str: from}) + "(" + x + ")"
+}
+
+// encode writes the code to encode the value x of type t.
+func (g *generator) encode(x string, t *typeInfo, addressable bool) {
+	switch {
+	case t.marshal == valueRecv, t.marshal == ptrRecv && addressable:
+		g.check("%s.MarshalMsgPack(enc)", operand(x))
+		return
+	case t.marshal == ptrRecv:
+		g.check("enc.Encode(%s)", x)
+		return
+	}
+
+	switch t.kind {
+	case boolKind:
+		g.check("enc.PackBool(%s)", g.conv(t, "bool", x))
+	case intKind:
+		g.check("enc.PackInt(%s)", g.conv(t, "int64", x))
+	case uintKind:
+		g.check("enc.PackUint(%s)", g.conv(t, "uint64", x))
+	case floatKind:
+		if t.bits == 32 {
+			g.check("enc.PackFloat32(%s)", g.conv(t, "float32", x))
+		} else {
+			g.check("enc.PackFloat(%s)", g.conv(t, "float64", x))
+		}
+	case stringKind:
+		g.check("enc.PackString(%s)", g.conv(t, "string", x))
+	case bytesKind:
+		g.check("enc.PackBinary(%s)", g.conv(t, "[]byte", x))
+	case timeKind:
+		g.check("enc.PackTimestamp(%s)", x)
+	case ptrKind:
+		g.printf("if %s == nil {\n", x)
+		g.check("enc.PackNil()")
+		g.printf("} else {\n")
+		g.encode("*"+x, t.elem, true)
+		g.printf("}\n")
+	case sliceKind:
+		g.printf("if %s == nil {\n", x)
+		g.check("enc.PackNil()")
+		g.printf("} else {\n")
+		g.encodeArray(x, t, true)
+		g.printf("}\n")
+	case arrayKind:
+		g.encodeArray(x, t, addressable)
+	case mapKind:
+		k, v := g.newVar("k"), g.newVar("v")
+		g.printf("if %s == nil {\n", x)
+		g.check("enc.PackNil()")
+		g.printf("} else if enc.SortMapKeys() {\n")
+		g.check("enc.Encode(%s)", x)
+		g.printf("} else {\n")
+		g.check("enc.PackMapLen(int64(len(%s)))", x)
+		g.printf("for %s, %s := range %s {\n", k, v, x)
+		g.encode(k, t.key, false)
+		g.encode(v, t.elem, false)
+		g.printf("}\n")
+		g.printf("}\n")
+	default:
+		g.check("enc.Encode(%s)", x)
+	}
+}
+
+func (g *generator) encodeArray(x string, t *typeInfo, addressable bool) {
+	i := g.newVar("i")
+	g.check("enc.PackArrayLen(int64(len(%s)))", x)
+	g.printf("for %s := range %s {\n", i, x)
+	g.encode(operand(x)+"["+i+"]", t.elem, addressable)
+	g.printf("}\n")
+}
+
+// decodeNext writes the code to decode the next value in the stream to x.
+func (g *generator) decodeNext(x string, t *typeInfo) {
+	if t.kind == fallbackKind && t.unmarshal == noRecv {
+		g.save("dec.Decode(&%s)", x)
+		return
+	}
+	g.unpack()
+	g.decodeCurrent(x, t)
+}
+
+// decodeCurrent writes the code to decode the current value to x.
+func (g *generator) decodeCurrent(x string, t *typeInfo) {
+	if t.unmarshal != noRecv {
+		g.save("%s.UnmarshalMsgPack(dec)", operand(x))
+		return
+	}
+
+	switch t.kind {
+	case boolKind:
+		g.convert(x, t, "dec.ConvertBool()", "bool")
+	case intKind:
+		g.convert(x, t, fmt.Sprintf("dec.ConvertInt(%d)", t.bits), "int64")
+	case uintKind:
+		g.convert(x, t, fmt.Sprintf("dec.ConvertUint(%d)", t.bits), "uint64")
+	case floatKind:
+		g.convert(x, t, fmt.Sprintf("dec.ConvertFloat(%d)", t.bits), "float64")
+	case stringKind:
+		g.convert(x, t, "dec.ConvertString()", "string")
+	case bytesKind:
+		g.convert(x, t, "dec.ConvertBytes()", "[]byte")
+	case timeKind:
+		g.convert(x, t, "dec.ConvertTime()", t.str)
+	case ptrKind:
+		g.printf("if dec.Type() == msgpack.Nil {\n")
+		g.printf("%s = nil\n", x)
+		g.printf("} else {\n")
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", x, x, g.typeStr(t.elem))
+		g.decodeCurrent("*"+x, t.elem)
+		g.printf("}\n")
+	case sliceKind:
+		i, n, s := g.newVar("i"), g.newVar("n"), g.newVar("s")
+		g.printf("switch dec.Type() {\n")
+		g.printf("case msgpack.Nil:\n")
+		g.printf("%s = %s[:0]\n", x, operand(x))
+		g.printf("case msgpack.ArrayLen:\n")
+		g.printf("if %s := dec.Len(); %s > cap(%s) {\n", n, n, x)
+		g.printf("%s := make(%s, %s)\n", s, g.typeStr(t), n)
+		g.printf("copy(%s, %s)\n", s, x)
+		g.printf("%s = %s\n", x, s)
+		g.printf("} else {\n")
+		g.printf("%s = %s[:%s]\n", x, operand(x), n)
+		g.printf("}\n")
+		g.printf("for %s := range %s {\n", i, x)
+		g.decodeNext(operand(x)+"["+i+"]", t.elem)
+		g.printf("}\n")
+		g.printf("default:\n")
+		g.convertError("reflect.TypeOf(" + x + ")")
+		g.printf("}\n")
+	case arrayKind:
+		i, n, z := g.newVar("i"), g.newVar("n"), g.newVar("z")
+		g.printf("switch dec.Type() {\n")
+		g.printf("case msgpack.Nil, msgpack.ArrayLen:\n")
+		g.printf("%s := 0\n", n)
+		g.printf("if dec.Type() == msgpack.ArrayLen {\n%s = dec.Len()\n}\n", n)
+		g.printf("for %s := 0; %s < %s; %s++ {\n", i, i, n, i)
+		g.printf("if %s >= len(%s) {\n", i, x)
+		g.unpack()
+		g.skip()
+		g.printf("continue\n")
+		g.printf("}\n")
+		g.decodeNext(operand(x)+"["+i+"]", t.elem)
+		g.printf("}\n")
+		g.printf("var %s %s\n", z, g.typeStr(t.elem))
+		g.printf("for %s := %s; %s < len(%s); %s++ {\n", i, n, i, x, i)
+		g.printf("%s[%s] = %s\n", operand(x), i, z)
+		g.printf("}\n")
+		g.printf("default:\n")
+		g.convertError("reflect.TypeOf(" + x + ")")
+		g.printf("}\n")
+	case mapKind:
+		i, n, k, v := g.newVar("i"), g.newVar("n"), g.newVar("k"), g.newVar("v")
+		g.printf("if dec.Type() != msgpack.MapLen {\n")
+		g.convertError("reflect.TypeOf(" + x + ")")
+		g.printf("} else {\n")
+		g.printf("if %s == nil {\n%s = make(%s)\n}\n", x, x, g.typeStr(t))
+		g.printf("for %s, %s := 0, dec.Len(); %s < %s; %s++ {\n", i, n, i, n, i)
+		g.printf("var %s %s\n", k, g.typeStr(t.key))
+		g.decodeNext(k, t.key)
+		g.printf("var %s %s\n", v, g.typeStr(t.elem))
+		g.decodeNext(v, t.elem)
+		g.printf("%s[%s] = %s\n", operand(x), k, v)
+		g.printf("}\n")
+		g.printf("}\n")
+	default:
+		panic(generateError{fmt.Errorf("cannot decode type %s", t.str)})
+	}
+}
+
+// convert writes the code to assign the result of a Decoder Convert method
+// to x.
+func (g *generator) convert(x string, t *typeInfo, call string, result string) {
+	g.printf("if v, err := %s; err != nil {\n", call)
+	g.printf("if err := saveError(err); err != nil {\nreturn err\n}\n")
+	g.printf("} else {\n")
+	if t.str == result {
+		g.printf("%s = v\n", x)
+	} else {
+		g.printf("%s = %s(v)\n", x, g.typeStr(t))
+	}
+	g.printf("}\n")
+}
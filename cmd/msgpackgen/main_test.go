@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	const dir = "internal/gentest"
+	want, err := ioutil.ReadFile(filepath.Join(dir, "types_msgpack.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := []string{"Basic", "Tagged", "Array", "Embed", "Containers", "Mode", "List"}
+	command := "msgpackgen -type " + strings.Join(types, ",") + " -output types_msgpack.go"
+	got, err := generate(dir, types, "types_msgpack.go", command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s/types_msgpack.go is out of date; run go generate", dir)
+	}
+}
+
+var generateErrorTests = []struct {
+	src  string
+	typ  string
+	want string
+}{
+	{"type T struct{}", "U", "type U not found"},
+	{"type T struct{ A int `msgpack:\",bad\"` }", "T", "unknown field tag bad"},
+	{"type T struct{ A []int `empty:\"x\"` }", "T", "unsupported type []int"},
+	{"type T struct{ A int `empty:\"x\"` }", "T", "error parsing field empty field T.A"},
+	{"type T struct{ *B }; type B struct{}", "T", "embedded pointer field B"},
+	{"import \"time\"; type T struct{ time.Location }", "T", "cannot determine fields of embedded field time.Location"},
+	{"import \"time\"; type T struct{ D time.Duration `msgpack:\",omitempty\"` }", "T", "cannot determine empty value of field T.D"},
+	{"type T struct{ A int `msgpack:\",inline\"` }", "T", "inline field T.A does not have a struct type"},
+	{"type T struct{ A *B `msgpack:\",inline\"` }; type B struct{}", "T", "inline field T.A does not have a struct type"},
+	{"type T struct{ A []int `msgpack:\",remain\"` }", "T", "remain field T.A is not a map with string keys"},
+	{"type T struct{ A, B map[string]int `msgpack:\",remain\"` }", "T", "type T has more than one remain field"},
+	{"type T struct{ A int `msgpack:\",array\"`; B map[string]int `msgpack:\",remain\"` }", "T", "array type T has remain field"},
+	{"type T = int", "T", "type alias T"},
+	{"type T interface{}", "T", "cannot generate methods for type T"},
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "msgpackgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tt := range generateErrorTests {
+		err := ioutil.WriteFile(filepath.Join(dir, "x.go"), []byte("package x\n"+tt.src+"\n"), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = generate(dir, []string{tt.typ}, "x_msgpack.go", "msgpackgen")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
@@ -53,6 +53,9 @@ const (
 	negFixIntCodeMax = 0xff
 )
 
+// timestampExtension is the extension type for timestamps.
+const timestampExtension = -1
+
 type aborted struct{ err error }
 
 func abort(err error) { panic(aborted{err}) }
@@ -71,12 +74,13 @@ type field struct {
 	name      string
 	omitEmpty bool
 	array     bool
+	remain    bool
 	index     []int
 	typ       reflect.Type
 	empty     reflect.Value
 }
 
-func collectFields(fields []*field, t reflect.Type, visited map[reflect.Type]bool, depth map[string]int, index []int) []*field {
+func collectFields(fields []*field, t reflect.Type, opts FieldOptions, visited map[reflect.Type]bool, depth map[string]int, index []int) []*field {
 	// Break recursion.
 	if visited[t] {
 		return fields
@@ -94,20 +98,34 @@ func collectFields(fields []*field, t reflect.Type, visited map[reflect.Type]boo
 			name      string
 			omitEmpty bool
 			array     bool
+			inline    bool
+			remain    bool
 		)
-		for i, p := range strings.Split(sf.Tag.Get("msgpack"), ",") {
+		tag, ok := sf.Tag.Lookup("msgpack")
+		jsonTag := false
+		if !ok && opts.JSONTags {
+			tag, jsonTag = sf.Tag.Lookup("json")
+		}
+		for i, p := range strings.Split(tag, ",") {
 			if i == 0 {
 				name = p
 			} else if p == "omitempty" {
 				omitEmpty = true
+			} else if jsonTag {
+				// Ignore other json options.
 			} else if p == "array" {
 				array = true
+			} else if p == "inline" {
+				inline = true
+			} else if p == "remain" {
+				remain = true
 			} else {
 				panic(fmt.Errorf("msgpack: unknown field tag %s for type %s", p, t.Name()))
 			}
 		}
 
-		if name == "-" {
+		// The json field tag "-," specifies the name "-".
+		if name == "-" && !(jsonTag && tag == "-,") {
 			// Skip field when field tag starts with "-".
 			continue
 		}
@@ -117,14 +135,33 @@ func collectFields(fields []*field, t reflect.Type, visited map[reflect.Type]boo
 			ft = ft.Elem()
 		}
 
-		if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
-			// Flatten anonymous struct field.
-			fields = collectFields(fields, ft, visited, depth, append(index, i))
+		if inline || (name == "" && sf.Anonymous && ft.Kind() == reflect.Struct) {
+			if ft.Kind() != reflect.Struct {
+				panic(fmt.Errorf("msgpack: inline field %s.%s is not a struct", t.Name(), sf.Name))
+			}
+			// Flatten anonymous or inline struct field.
+			fields = collectFields(fields, ft, opts, visited, depth, append(index, i))
+			continue
+		}
+
+		if remain {
+			if sf.Type.Kind() != reflect.Map || sf.Type.Key().Kind() != reflect.String {
+				panic(fmt.Errorf("msgpack: remain field %s.%s is not a map with string keys", t.Name(), sf.Name))
+			}
+			f := &field{
+				name:   sf.Name,
+				remain: true,
+				index:  make([]int, len(index)+1),
+				typ:    sf.Type,
+			}
+			copy(f.index, index)
+			f.index[len(index)] = i
+			fields = append(fields, f)
 			continue
 		}
 
 		if name == "" {
-			name = sf.Name
+			name = opts.fieldName(sf.Name)
 		}
 
 		// Check for name collisions.
@@ -193,16 +230,47 @@ func collectFields(fields []*field, t reflect.Type, visited map[reflect.Type]boo
 	return fields
 }
 
-func fieldsForType(t reflect.Type) ([]*field, bool) {
-	fields := collectFields(nil, t, make(map[reflect.Type]bool), make(map[string]int), nil)
+// fieldsForType returns the fields of struct type t, the field with the
+// "remain" option and whether t is encoded as an array.
+func fieldsForType(t reflect.Type, opts FieldOptions) ([]*field, *field, bool) {
+	fields := collectFields(nil, t, opts, make(map[reflect.Type]bool), make(map[string]int), nil)
+	var remain *field
 	array := false
+	j := 0
 	for _, field := range fields {
+		if field.remain {
+			if remain != nil {
+				panic(fmt.Errorf("msgpack: type %s has more than one remain field", t.Name()))
+			}
+			remain = field
+			continue
+		}
 		if field.array {
 			array = true
-			break
 		}
+		fields[j] = field
+		j++
+	}
+	fields = fields[:j]
+	if array && remain != nil {
+		panic(fmt.Errorf("msgpack: array type %s has remain field", t.Name()))
 	}
-	return fields, array
+	return fields, remain, array
+}
+
+// fieldByIndexAlloc is like fieldByIndex, but allocates nil pointers to
+// embedded structs.
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
 }
 
 func fieldByIndex(v reflect.Value, index []int) reflect.Value {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"time"
 )
 
 type arrayLen uint32
@@ -78,11 +79,13 @@ func unpack(p []byte) ([]interface{}, error) {
 //  int64       PackInt
 //  uint64      PackUint
 //  float64     PackFloat
+//  float32     PackFloat32
 //  arrayLen    PackArrayLen
 //  mapLen      PackMapLen
 //  string      PackString(s, false)
 //  []byte      PackBytes(s, true)
 //  extension   PackExtension(k, d)
+//  time.Time   PackTimestamp
 func pack(vs ...interface{}) ([]byte, error) {
 	var buf bytes.Buffer
 	enc := NewEncoder(&buf)
@@ -97,6 +100,8 @@ func pack(vs ...interface{}) ([]byte, error) {
 			err = enc.PackBool(v)
 		case float64:
 			err = enc.PackFloat(v)
+		case float32:
+			err = enc.PackFloat32(v)
 		case arrayLen:
 			err = enc.PackArrayLen(int64(v))
 		case mapLen:
@@ -107,6 +112,8 @@ func pack(vs ...interface{}) ([]byte, error) {
 			err = enc.PackBinary(v)
 		case extension:
 			err = enc.PackExtension(v.k, []byte(v.d))
+		case time.Time:
+			err = enc.PackTimestamp(v)
 		case nil:
 			err = enc.PackNil()
 		default:
@@ -0,0 +1,276 @@
+package msgpack
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// The Convert methods convert the current value to a Go type using the same
+// rules as Decode. They are intended for hand-written and generated
+// implementations of the Unmarshaler interface.
+//
+// If the current value cannot be converted, the Convert methods skip the
+// value and return a *DecodeConvertError. Other errors are from skipping the
+// value.
+
+var (
+	boolType           = reflect.TypeOf(false)
+	byteSliceType      = reflect.TypeOf([]byte(nil))
+	stringType         = reflect.TypeOf("")
+	float32Type        = reflect.TypeOf(float32(0))
+	float64Type        = reflect.TypeOf(float64(0))
+	emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	interfaceSliceType = reflect.TypeOf([]interface{}(nil))
+	interfaceMapType   = reflect.TypeOf(map[string]interface{}(nil))
+	intTypes           = map[int]reflect.Type{
+		0:  reflect.TypeOf(int(0)),
+		8:  reflect.TypeOf(int8(0)),
+		16: reflect.TypeOf(int16(0)),
+		32: reflect.TypeOf(int32(0)),
+		64: reflect.TypeOf(int64(0)),
+	}
+	uintTypes = map[int]reflect.Type{
+		0:  reflect.TypeOf(uint(0)),
+		8:  reflect.TypeOf(uint8(0)),
+		16: reflect.TypeOf(uint16(0)),
+		32: reflect.TypeOf(uint32(0)),
+		64: reflect.TypeOf(uint64(0)),
+	}
+)
+
+// ConvertBool returns the current value as a bool.
+func (d *Decoder) ConvertBool() (bool, error) {
+	x, src, ok := d.convertBool()
+	if !ok {
+		return false, d.convertError(boolType, src)
+	}
+	return x, nil
+}
+
+// ConvertInt returns the current value as a signed integer with the
+// specified bit size. Bit sizes 0, 8, 16, 32, and 64 correspond to int, int8,
+// int16, int32, and int64.
+func (d *Decoder) ConvertInt(bitSize int) (int64, error) {
+	t := intTypes[bitSize]
+	if t == nil {
+		panic("msgpack: invalid bit size " + strconv.Itoa(bitSize))
+	}
+	x, src, ok := d.convertInt()
+	if !ok {
+		return 0, d.convertError(t, src)
+	}
+	if bits := uint(t.Bits()); bits < 64 && (x < -1<<(bits-1) || x >= 1<<(bits-1)) {
+		return 0, d.convertError(t, x)
+	}
+	return x, nil
+}
+
+// ConvertUint returns the current value as an unsigned integer with the
+// specified bit size. Bit sizes 0, 8, 16, 32, and 64 correspond to uint,
+// uint8, uint16, uint32, and uint64.
+func (d *Decoder) ConvertUint(bitSize int) (uint64, error) {
+	t := uintTypes[bitSize]
+	if t == nil {
+		panic("msgpack: invalid bit size " + strconv.Itoa(bitSize))
+	}
+	x, src, ok := d.convertUint()
+	if !ok {
+		return 0, d.convertError(t, src)
+	}
+	if bits := uint(t.Bits()); bits < 64 && x >= 1<<bits {
+		return 0, d.convertError(t, x)
+	}
+	return x, nil
+}
+
+// ConvertFloat returns the current value as a floating-point number with the
+// specified bit size. Bit sizes 32 and 64 correspond to float32 and float64.
+func (d *Decoder) ConvertFloat(bitSize int) (float64, error) {
+	var t reflect.Type
+	switch bitSize {
+	case 32:
+		t = float32Type
+	case 64:
+		t = float64Type
+	default:
+		panic("msgpack: invalid bit size " + strconv.Itoa(bitSize))
+	}
+	x, src, ok := d.convertFloat(bitSize)
+	if !ok {
+		return 0, d.convertError(t, src)
+	}
+	return x, nil
+}
+
+// ConvertString returns the current value as a string.
+func (d *Decoder) ConvertString() (string, error) {
+	switch d.Type() {
+	case Binary, String:
+		return d.String(), nil
+	default:
+		return "", d.convertError(stringType, nil)
+	}
+}
+
+// ConvertBytes returns the current value as a byte slice. A nil value is
+// returned as a nil slice.
+func (d *Decoder) ConvertBytes() ([]byte, error) {
+	switch d.Type() {
+	case Nil:
+		return nil, nil
+	case Binary, String:
+		return d.Bytes(), nil
+	default:
+		return nil, d.convertError(byteSliceType, nil)
+	}
+}
+
+// ConvertTime returns the current value as a time.Time. A nil value is
+// returned as the zero time.
+func (d *Decoder) ConvertTime() (time.Time, error) {
+	x, ok := d.convertTime()
+	if !ok {
+		return time.Time{}, d.convertError(timeType, nil)
+	}
+	return x, nil
+}
+
+// ConvertError skips the current value and returns a *DecodeConvertError
+// for the conversion of the value to destType.
+func (d *Decoder) ConvertError(destType reflect.Type) error {
+	return d.convertError(destType, nil)
+}
+
+func (d *Decoder) convertError(destType reflect.Type, srcValue interface{}) error {
+	err := &DecodeConvertError{
+		SrcType:  d.Type(),
+		SrcValue: srcValue,
+		DestType: destType,
+		Offset:   d.valueOffset,
+	}
+	if err := d.Skip(); err != nil {
+		return err
+	}
+	return err
+}
+
+// The convert functions below implement the conversion rules shared by
+// the reflection decoders and the Convert methods. If a value cannot be
+// converted, the functions return false and the source value to report in
+// the error.
+
+func (d *Decoder) convertBool() (bool, interface{}, bool) {
+	switch d.Type() {
+	case Bool:
+		return d.Bool(), nil, true
+	case Int:
+		if d.strictNumbers {
+			return false, d.Int(), false
+		}
+		return d.Int() != 0, nil, true
+	case Uint:
+		if d.strictNumbers {
+			return false, d.Uint(), false
+		}
+		return d.Uint() != 0, nil, true
+	default:
+		return false, nil, false
+	}
+}
+
+func (d *Decoder) convertInt() (int64, interface{}, bool) {
+	switch d.Type() {
+	case Int:
+		return d.Int(), nil, true
+	case Uint:
+		n := d.Uint()
+		x := int64(n)
+		if x < 0 {
+			return 0, n, false
+		}
+		return x, nil, true
+	case Float:
+		f := d.Float()
+		x := int64(f)
+		if float64(x) != f || d.strictNumbers {
+			return 0, f, false
+		}
+		return x, nil, true
+	default:
+		return 0, nil, false
+	}
+}
+
+func (d *Decoder) convertUint() (uint64, interface{}, bool) {
+	switch d.Type() {
+	case Uint:
+		return d.Uint(), nil, true
+	case Int:
+		i := d.Int()
+		if i < 0 {
+			return 0, i, false
+		}
+		return uint64(i), nil, true
+	case Float:
+		f := d.Float()
+		x := uint64(f)
+		if float64(x) != f || d.strictNumbers {
+			return 0, f, false
+		}
+		return x, nil, true
+	default:
+		return 0, nil, false
+	}
+}
+
+func (d *Decoder) convertFloat(bitSize int) (float64, interface{}, bool) {
+	switch d.Type() {
+	case Int:
+		// Integers must be represented exactly. Conversions of floats
+		// outside the range of the integer type are implementation
+		// specific, so check the range before converting back.
+		i := d.Int()
+		x := roundFloat(float64(i), bitSize)
+		if x >= 1<<63 || int64(x) != i {
+			return 0, i, false
+		}
+		return x, nil, true
+	case Uint:
+		n := d.Uint()
+		x := roundFloat(float64(n), bitSize)
+		if x >= 1<<64 || uint64(x) != n {
+			return 0, n, false
+		}
+		return x, nil, true
+	case Float:
+		x := d.Float()
+		if d.strictNumbers && bitSize == 32 && !d.IsFloat32() && float64(float32(x)) != x && !math.IsNaN(x) {
+			return 0, x, false
+		}
+		return x, nil, true
+	default:
+		return 0, nil, false
+	}
+}
+
+// roundFloat returns x rounded to a float with the given bit size.
+func roundFloat(x float64, bitSize int) float64 {
+	if bitSize == 32 {
+		return float64(float32(x))
+	}
+	return x
+}
+
+func (d *Decoder) convertTime() (time.Time, bool) {
+	switch {
+	case d.Type() == Nil:
+		return time.Time{}, true
+	case d.IsTimestamp():
+		x, err := d.Timestamp()
+		return x, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,77 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var convertTests = []struct {
+	hs      string
+	convert func(d *Decoder) (interface{}, error)
+	v       interface{}
+	// Destination type for conversion errors.
+	errType interface{}
+}{
+	{"c3", func(d *Decoder) (interface{}, error) { return d.ConvertBool() }, true, nil},
+	{"01", func(d *Decoder) (interface{}, error) { return d.ConvertBool() }, true, nil},
+	{"a0", func(d *Decoder) (interface{}, error) { return d.ConvertBool() }, nil, false},
+	{"7f", func(d *Decoder) (interface{}, error) { return d.ConvertInt(8) }, int64(127), nil},
+	{"cc80", func(d *Decoder) (interface{}, error) { return d.ConvertInt(8) }, nil, int8(0)},
+	{"d0ff", func(d *Decoder) (interface{}, error) { return d.ConvertInt(0) }, int64(-1), nil},
+	{"cb3ff8000000000000", func(d *Decoder) (interface{}, error) { return d.ConvertInt(16) }, nil, int16(0)},
+	{"cdffff", func(d *Decoder) (interface{}, error) { return d.ConvertUint(16) }, uint64(0xffff), nil},
+	{"ce00010000", func(d *Decoder) (interface{}, error) { return d.ConvertUint(16) }, nil, uint16(0)},
+	{"ff", func(d *Decoder) (interface{}, error) { return d.ConvertUint(0) }, nil, uint(0)},
+	{"02", func(d *Decoder) (interface{}, error) { return d.ConvertFloat(32) }, float64(2), nil},
+	{"cb7fefffffffffffff", func(d *Decoder) (interface{}, error) { return d.ConvertFloat(32) }, math.MaxFloat64, nil},
+	{"a161", func(d *Decoder) (interface{}, error) { return d.ConvertString() }, "a", nil},
+	{"c40162", func(d *Decoder) (interface{}, error) { return d.ConvertString() }, "b", nil},
+	{"c0", func(d *Decoder) (interface{}, error) { return d.ConvertString() }, nil, ""},
+	{"c0", func(d *Decoder) (interface{}, error) { return d.ConvertBytes() }, []byte(nil), nil},
+	{"a161", func(d *Decoder) (interface{}, error) { return d.ConvertBytes() }, []byte("a"), nil},
+	{"d6ff00000001", func(d *Decoder) (interface{}, error) { return d.ConvertTime() }, time.Unix(1, 0), nil},
+	{"c0", func(d *Decoder) (interface{}, error) { return d.ConvertTime() }, time.Time{}, nil},
+	{"9201a0", func(d *Decoder) (interface{}, error) { return d.ConvertTime() }, nil, time.Time{}},
+}
+
+func TestConvert(t *testing.T) {
+	for _, tt := range convertTests {
+		// Append a value to check that the value is skipped on error.
+		p, err := hex.DecodeString(tt.hs + "2a")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.hs, err)
+		}
+		d := NewDecoder(bytes.NewReader(p))
+		if err := d.Unpack(); err != nil {
+			t.Errorf("%s: Unpack returned error %v", tt.hs, err)
+			continue
+		}
+		v, err := tt.convert(d)
+		if tt.errType != nil {
+			e, ok := err.(*DecodeConvertError)
+			if !ok {
+				t.Errorf("%s: got error %v, want *DecodeConvertError", tt.hs, err)
+				continue
+			}
+			if e.DestType != reflect.TypeOf(tt.errType) {
+				t.Errorf("%s: got DestType %v, want %v", tt.hs, e.DestType, reflect.TypeOf(tt.errType))
+			}
+		} else if err != nil {
+			t.Errorf("%s: returned error %v", tt.hs, err)
+			continue
+		} else if tv, ok := v.(time.Time); ok {
+			if !tv.Equal(tt.v.(time.Time)) {
+				t.Errorf("%s: got %v, want %v", tt.hs, v, tt.v)
+			}
+		} else if !reflect.DeepEqual(v, tt.v) {
+			t.Errorf("%s: got %#v, want %#v", tt.hs, v, tt.v)
+		}
+		if err := d.Unpack(); err != nil || d.Int() != 42 {
+			t.Errorf("%s: next value not decoded, err=%v", tt.hs, err)
+		}
+	}
+}
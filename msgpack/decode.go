@@ -1,9 +1,11 @@
 package msgpack
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 )
 
@@ -11,6 +13,48 @@ import (
 type decodeState struct {
 	*Decoder
 	errSaved error
+
+	// path is the path from the top-level value to the current value.
+	path []pathElem
+}
+
+// pathElem is a struct field, array index or map key in the path to a value.
+type pathElem struct {
+	// name is the name of a struct field.
+	name string
+	// key is a map key.
+	key reflect.Value
+	// index is an array index. The index is used when name is empty and
+	// key is not valid.
+	index int
+}
+
+func (ds *decodeState) pushPath(pe pathElem) {
+	ds.path = append(ds.path, pe)
+}
+
+func (ds *decodeState) popPath() {
+	ds.path = ds.path[:len(ds.path)-1]
+}
+
+func (ds *decodeState) pathString() string {
+	var buf strings.Builder
+	for _, pe := range ds.path {
+		switch {
+		case pe.name != "":
+			buf.WriteByte('.')
+			buf.WriteString(pe.name)
+		case pe.key.IsValid():
+			if pe.key.Kind() == reflect.String {
+				fmt.Fprintf(&buf, "[%q]", pe.key.String())
+			} else {
+				fmt.Fprintf(&buf, "[%v]", pe.key.Interface())
+			}
+		default:
+			fmt.Fprintf(&buf, "[%d]", pe.index)
+		}
+	}
+	return buf.String()
 }
 
 func (ds *decodeState) unpack() {
@@ -25,12 +69,38 @@ func (ds *decodeState) skip() {
 	}
 }
 
+// saveError saves err if err is an error that does not stop decoding.
+// Otherwise, saveError aborts decoding with err.
+func (ds *decodeState) saveError(err error) {
+	switch err := err.(type) {
+	case nil:
+	case *DecodeConvertError:
+		if ds.errSaved == nil {
+			if err.Path == "" {
+				err.Path = ds.pathString()
+			}
+			if err.Offset == 0 {
+				err.Offset = ds.valueOffset
+			}
+			ds.errSaved = err
+		}
+	case *UnknownFieldError:
+		if ds.errSaved == nil {
+			ds.errSaved = err
+		}
+	default:
+		abort(err)
+	}
+}
+
 func (ds *decodeState) saveErrorAndSkip(destValue reflect.Value, srcValue interface{}) {
 	if ds.errSaved == nil {
 		ds.errSaved = &DecodeConvertError{
 			SrcType:  ds.Type(),
 			SrcValue: srcValue,
 			DestType: destValue.Type(),
+			Path:     ds.pathString(),
+			Offset:   ds.valueOffset,
 		}
 	}
 	ds.skip()
@@ -47,6 +117,33 @@ func (ds *decodeState) saveErrorAndSkip(destValue reflect.Value, srcValue interf
 // the stream into the value pointed at by the pointer. If the pointer is nil,
 // Decode allocates a new value for it to point to.
 //
+// To decode a MessagePack value into an empty interface value, Decode stores
+// one of these in the interface value:
+//
+//	bool, for MessagePack booleans
+//	int64, uint64 and float64, for MessagePack numbers
+//	string, for MessagePack strings
+//	[]byte, for MessagePack binary
+//	[]interface{}, for MessagePack arrays
+//	map[string]interface{}, for MessagePack maps
+//	nil, for MessagePack nil
+//
+// The decoder methods UseNumber, UseInt64, UseBinaryAsString and SetMapType
+// change these types.
+//
+// To decode a MessagePack timestamp extension into an interface value, Decode
+// stores a time.Time in the interface value.
+//
+// To decode a value to a type with a registered extension, Decode calls the
+// extension's Decode function. To decode an extension into an interface
+// value, Decode uses the extension registered for the extension type, if any.
+// See RegisterExtension.
+//
+// To decode a MessagePack string or binary value into a value implementing
+// encoding.BinaryUnmarshaler or encoding.TextUnmarshaler, Decode calls the
+// UnmarshalBinary or UnmarshalText method with the string or binary data. Map
+// keys implementing encoding.TextUnmarshaler are decoded with UnmarshalText.
+//
 // To decode a MessagePack array into a slice, Decode sets the slice length to
 // the length of the MessagePack array or reallocates the slice if there is
 // insufficient capaicity. Slice elments are not cleared before decoding the
@@ -62,7 +159,15 @@ func (ds *decodeState) saveErrorAndSkip(destValue reflect.Value, srcValue interf
 // MessagePack number overflows the target type, Decode skips that field and
 // completes the decoding as best it can.  If no more serious errors are
 // encountered, Decode returns an DecodeConvertError describing the earliest
-// such error.
+// such error. Int and Uint values that cannot be represented exactly by the
+// target float type are not appropriate for the type. Float values that
+// overflow a float32 are decoded as infinities unless UseStrictNumbers is
+// called. Map keys that do not match a struct field are skipped in the same
+// way when DisallowUnknownFields is set; the error for these keys is an
+// UnknownFieldError. If the struct has a field with the "remain" option,
+// Decode stores the map entries that do not match a struct field in that
+// field instead. Decode matches map keys to struct fields using the decoder's
+// field options. See SetFieldOptions.
 func (d *Decoder) Decode(v interface{}) (err error) {
 	defer handleAbort(&err)
 	ds := &decodeState{
@@ -77,32 +182,94 @@ func (d *Decoder) Decode(v interface{}) (err error) {
 	if rv.Kind() == reflect.Ptr {
 		rv = rv.Elem()
 	}
-	decoderForType(rv.Type(), nil)(ds, rv)
+	decoderForType(rv.Type(), &decodeBuilder{opts: d.fieldOptions})(ds, rv)
+	return ds.errSaved
+}
+
+// Unmarshal decodes the MessagePack encoded value in data and stores the
+// result in the value pointed to by v. See the documentation for Decode for
+// details about the conversion of MessagePack values to Go values. Unmarshal
+// returns an error if data contains more than one value.
+func Unmarshal(data []byte, v interface{}) error {
+	d := newBytesDecoder(data)
+	err := d.Decode(v)
+	switch err.(type) {
+	case nil, *DecodeConvertError, *UnknownFieldError:
+	default:
+		return err
+	}
+	if r := d.r.(*bytesReader); r.off < len(r.p) {
+		return errors.New("msgpack: unexpected data after top-level value")
+	}
+	return err
+}
+
+// DecodeStruct decodes the current value in the stream to the struct pointed
+// to by v using the rules of Decode for structs. DecodeStruct ignores the
+// UnmarshalMsgPack method and other methods of the struct type, but not the
+// methods of the field types. UnmarshalMsgPack methods call DecodeStruct to
+// decode a struct in the same way as Decode would without the method.
+func (d *Decoder) DecodeStruct(v interface{}) (err error) {
+	defer handleAbort(&err)
+	ds := &decodeState{
+		Decoder: d,
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		ds.skip()
+		return errors.New("msgpack: argument to DecodeStruct must be non-nil pointer to struct")
+	}
+	structDecoderForType(rv.Elem().Type(), d.fieldOptions)(ds, rv.Elem())
 	return ds.errSaved
 }
 
 var decodeFuncCache struct {
 	sync.RWMutex
-	m map[reflect.Type]decodeFunc
+	m map[typeKey]decodeFunc
+}
+
+// structDecodeFuncCache holds the struct decoders used by DecodeStruct.
+var structDecodeFuncCache struct {
+	sync.RWMutex
+	m map[typeKey]decodeFunc
+}
+
+func structDecoderForType(t reflect.Type, opts FieldOptions) decodeFunc {
+	k := typeKey{t, opts}
+	structDecodeFuncCache.RLock()
+	f, ok := structDecodeFuncCache.m[k]
+	structDecodeFuncCache.RUnlock()
+	if ok {
+		return f
+	}
+	f = (&decodeBuilder{opts: opts}).structDecoder(t)
+	structDecodeFuncCache.Lock()
+	if structDecodeFuncCache.m == nil {
+		structDecodeFuncCache.m = make(map[typeKey]decodeFunc)
+	}
+	structDecodeFuncCache.m[k] = f
+	structDecodeFuncCache.Unlock()
+	return f
 }
 
 type decodeFunc func(*decodeState, reflect.Value)
 
 type decodeBuilder struct {
-	m map[reflect.Type]decodeFunc
+	m    map[reflect.Type]decodeFunc
+	opts FieldOptions
 }
 
 func decoderForType(t reflect.Type, b *decodeBuilder) decodeFunc {
 	decodeFuncCache.RLock()
-	f, ok := decodeFuncCache.m[t]
+	f, ok := decodeFuncCache.m[typeKey{t, b.opts}]
 	decodeFuncCache.RUnlock()
 	if ok {
 		return f
 	}
 
 	save := false
-	if b == nil {
-		b = &decodeBuilder{m: make(map[reflect.Type]decodeFunc)}
+	if b.m == nil {
+		b.m = make(map[reflect.Type]decodeFunc)
 		save = true
 	} else if f, ok := b.m[t]; ok {
 		return f
@@ -111,16 +278,16 @@ func decoderForType(t reflect.Type, b *decodeBuilder) decodeFunc {
 	b.m[t] = func(ds *decodeState, v reflect.Value) {
 		f(ds, v)
 	}
-	f = b.decoder(t)
+	f = decoderExtensionDecoder{t, b.decoder(t)}.decode
 	b.m[t] = f
 
 	if save {
 		decodeFuncCache.Lock()
 		if decodeFuncCache.m == nil {
-			decodeFuncCache.m = make(map[reflect.Type]decodeFunc)
+			decodeFuncCache.m = make(map[typeKey]decodeFunc)
 		}
 		for t, f := range b.m {
-			decodeFuncCache.m[t] = f
+			decodeFuncCache.m[typeKey{t, b.opts}] = f
 		}
 		decodeFuncCache.Unlock()
 	}
@@ -128,9 +295,42 @@ func decoderForType(t reflect.Type, b *decodeBuilder) decodeFunc {
 }
 
 func (b *decodeBuilder) decoder(t reflect.Type) decodeFunc {
+	if ext := registeredExtensionForType(t); ext != nil {
+		return extensionDecoder{ext}.decode
+	}
 	if t.Kind() == reflect.Ptr && t.Implements(unmarshalerType) {
 		return unmarshalDecoder
 	}
+	if t == timeType {
+		return timeDecoder
+	}
+	var f decodeFunc
+	switch {
+	case t.Kind() != reflect.Ptr || ptrToNativeType(t):
+		f = b.kindDecoder(t)
+	case t.Implements(binaryUnmarshalerType):
+		f = byteUnmarshalDecoder(unmarshalBinary).decode
+	case t.Implements(textUnmarshalerType):
+		f = byteUnmarshalDecoder(unmarshalText).decode
+	default:
+		f = b.kindDecoder(t)
+	}
+	if t.Kind() != reflect.Ptr {
+		pt := reflect.PtrTo(t)
+		switch {
+		case pt.Implements(binaryUnmarshalerType):
+			f = byteUnmarshalAddrDecoder{f, unmarshalBinary}.decode
+		case pt.Implements(textUnmarshalerType):
+			f = byteUnmarshalAddrDecoder{f, unmarshalText}.decode
+		}
+		if pt.Implements(unmarshalerType) {
+			f = unmarshalAddrDecoder{f}.decode
+		}
+	}
+	return f
+}
+
+func (b *decodeBuilder) kindDecoder(t reflect.Type) decodeFunc {
 	var f decodeFunc
 	switch t.Kind() {
 	case reflect.Bool:
@@ -158,49 +358,23 @@ func (b *decodeBuilder) decoder(t reflect.Type) decodeFunc {
 	default:
 		f = decodeUnsupportedType
 	}
-	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(unmarshalerType) {
-		f = unmarshalAddrDecoder{f}.decode
-	}
 	return f
 }
 
 func boolDecoder(ds *decodeState, v reflect.Value) {
-	var x bool
-	switch ds.Type() {
-	case Bool:
-		x = ds.Bool()
-	case Int:
-		x = ds.Int() != 0
-	case Uint:
-		x = ds.Uint() != 0
-	default:
-		ds.saveErrorAndSkip(v, nil)
+	x, src, ok := ds.convertBool()
+	if !ok {
+		ds.saveErrorAndSkip(v, src)
 		return
 	}
 	v.SetBool(x)
 }
 
 func intDecoder(ds *decodeState, v reflect.Value) {
-	var x int64
-	switch ds.Type() {
-	case Int:
-		x = ds.Int()
-	case Uint:
-		n := ds.Uint()
-		x = int64(n)
-		if x < 0 {
-			ds.saveErrorAndSkip(v, n)
-			return
-		}
-	case Float:
-		f := ds.Float()
-		x = int64(f)
-		if float64(x) != f {
-			ds.saveErrorAndSkip(v, f)
-			return
-		}
-	default:
-		ds.saveErrorAndSkip(v, nil)
+	x, src, ok := ds.convertInt()
+	if !ok {
+		ds.saveErrorAndSkip(v, src)
+		return
 	}
 	if v.OverflowInt(x) {
 		ds.saveErrorAndSkip(v, x)
@@ -210,26 +384,9 @@ func intDecoder(ds *decodeState, v reflect.Value) {
 }
 
 func uintDecoder(ds *decodeState, v reflect.Value) {
-	var x uint64
-	switch ds.Type() {
-	case Uint:
-		x = ds.Uint()
-	case Int:
-		i := ds.Int()
-		if i < 0 {
-			ds.saveErrorAndSkip(v, i)
-			return
-		}
-		x = uint64(i)
-	case Float:
-		f := ds.Float()
-		x = uint64(f)
-		if float64(x) != f {
-			ds.saveErrorAndSkip(v, f)
-			return
-		}
-	default:
-		ds.saveErrorAndSkip(v, nil)
+	x, src, ok := ds.convertUint()
+	if !ok {
+		ds.saveErrorAndSkip(v, src)
 		return
 	}
 	if v.OverflowUint(x) {
@@ -240,41 +397,21 @@ func uintDecoder(ds *decodeState, v reflect.Value) {
 }
 
 func floatDecoder(ds *decodeState, v reflect.Value) {
-	var x float64
-	switch ds.Type() {
-	case Int:
-		i := ds.Int()
-		x = float64(i)
-		if int64(x) != i {
-			ds.saveErrorAndSkip(v, i)
-			return
-		}
-	case Uint:
-		n := ds.Uint()
-		x = float64(n)
-		if uint64(x) != n {
-			ds.saveErrorAndSkip(v, n)
-			return
-		}
-	case Float:
-		x = ds.Float()
-	default:
-		ds.saveErrorAndSkip(v, nil)
+	x, src, ok := ds.convertFloat(v.Type().Bits())
+	if !ok {
+		ds.saveErrorAndSkip(v, src)
 		return
 	}
 	v.SetFloat(x)
 }
 
 func stringDecoder(ds *decodeState, v reflect.Value) {
-	var x string
 	switch ds.Type() {
 	case Binary, String:
-		x = ds.String()
+		v.SetString(ds.String())
 	default:
 		ds.saveErrorAndSkip(v, nil)
-		return
 	}
-	v.SetString(x)
 }
 
 func byteSliceDecoder(ds *decodeState, v reflect.Value) {
@@ -292,6 +429,15 @@ func byteSliceDecoder(ds *decodeState, v reflect.Value) {
 	v.SetBytes(x)
 }
 
+func timeDecoder(ds *decodeState, v reflect.Value) {
+	x, ok := ds.convertTime()
+	if !ok {
+		ds.saveErrorAndSkip(v, nil)
+		return
+	}
+	v.Set(reflect.ValueOf(x))
+}
+
 func interfaceDecoder(ds *decodeState, v reflect.Value) {
 	if ds.Type() == Nil {
 		v.Set(reflect.Zero(v.Type()))
@@ -312,7 +458,7 @@ func interfaceDecoder(ds *decodeState, v reflect.Value) {
 	if (v.Kind() == reflect.Ptr ||
 		v.Kind() == reflect.Map ||
 		v.Kind() == reflect.Slice) && !v.IsNil() {
-		decoderForType(v.Type(), nil)(ds, v)
+		decoderForType(v.Type(), &decodeBuilder{opts: ds.fieldOptions})(ds, v)
 		return
 	}
 
@@ -325,7 +471,9 @@ type sliceArrayDecoder struct {
 
 func (dec sliceArrayDecoder) decodeArray(ds *decodeState, v reflect.Value) {
 	n := ds.Len()
+	ds.pushPath(pathElem{})
 	for i := 0; i < n; i++ {
+		ds.path[len(ds.path)-1].index = i
 		ds.unpack()
 		if i < v.Len() {
 			dec.elem(ds, v.Index(i))
@@ -333,6 +481,7 @@ func (dec sliceArrayDecoder) decodeArray(ds *decodeState, v reflect.Value) {
 			ds.skip()
 		}
 	}
+	ds.popPath()
 	if n < v.Len() {
 		z := reflect.Zero(v.Type().Elem())
 		for i := n; i < v.Len(); i++ {
@@ -358,10 +507,13 @@ func (dec sliceArrayDecoder) decodeSlice(ds *decodeState, v reflect.Value) {
 	} else {
 		v.SetLen(n)
 	}
+	ds.pushPath(pathElem{})
 	for i := 0; i < n; i++ {
+		ds.path[len(ds.path)-1].index = i
 		ds.unpack()
 		dec.elem(ds, v.Index(i))
 	}
+	ds.popPath()
 }
 
 func (b *decodeBuilder) sliceDecoder(t reflect.Type) decodeFunc {
@@ -392,7 +544,9 @@ func (dec *mapDecoder) decode(ds *decodeState, v reflect.Value) {
 
 		ds.unpack()
 		elem := reflect.New(v.Type().Elem()).Elem()
+		ds.pushPath(pathElem{key: key})
 		dec.elem(ds, elem)
+		ds.popPath()
 
 		v.SetMapIndex(key, elem)
 	}
@@ -400,13 +554,26 @@ func (dec *mapDecoder) decode(ds *decodeState, v reflect.Value) {
 
 func (b *decodeBuilder) mapDecoder(t reflect.Type) decodeFunc {
 	dec := &mapDecoder{
-		key:  decoderForType(t.Key(), b),
+		key:  b.mapKeyDecoder(t.Key()),
 		elem: decoderForType(t.Elem(), b),
 	}
 	return dec.decode
 }
 
+// mapKeyDecoder returns the decoder for map keys of type t. Map keys that
+// implement encoding.TextUnmarshaler are decoded from strings, even if they
+// also implement encoding.BinaryUnmarshaler.
+func (b *decodeBuilder) mapKeyDecoder(t reflect.Type) decodeFunc {
+	pt := reflect.PtrTo(t)
+	if t != timeType && t.Kind() != reflect.Ptr && pt.Implements(textUnmarshalerType) && !pt.Implements(unmarshalerType) && registeredExtensionForType(t) == nil {
+		f := byteUnmarshalAddrDecoder{decoderForType(t, b), unmarshalText}.decode
+		return decoderExtensionDecoder{t, f}.decode
+	}
+	return decoderForType(t, b)
+}
+
 type fieldDec struct {
+	name  string
 	index []int
 	f     decodeFunc
 	empty reflect.Value
@@ -416,8 +583,9 @@ func (fd *fieldDec) setEmpty(v reflect.Value) {
 	if !fd.empty.IsValid() {
 		return
 	}
-	fv := fieldByIndex(v, fd.index)
-	fv.Set(fd.empty)
+	if fv := fieldByIndex(v, fd.index); fv.IsValid() {
+		fv.Set(fd.empty)
+	}
 }
 
 type structArrayDecoder []*fieldDec
@@ -435,18 +603,35 @@ func (dec structArrayDecoder) decode(ds *decodeState, v reflect.Value) {
 		ds.unpack()
 		if i < len(dec) {
 			fd := dec[i]
-			fv := fieldByIndex(v, fd.index)
+			fv := fieldByIndexAlloc(v, fd.index)
+			ds.pushPath(pathElem{name: fd.name})
 			fd.f(ds, fv)
+			ds.popPath()
 		} else {
 			ds.skip()
 		}
 	}
 }
 
-type structDecoder map[string]*fieldDec
+type structDecoder struct {
+	fields map[string]*fieldDec
+	// fold maps lower case field names to fields for case-insensitive
+	// matching. The map is nil when matching is case-sensitive.
+	fold map[string]*fieldDec
+	// remain decodes the map entries that do not match a field to the
+	// field with the "remain" option.
+	remain *remainDec
+}
 
-func (dec structDecoder) decode(ds *decodeState, v reflect.Value) {
-	for _, fd := range dec {
+type remainDec struct {
+	name  string
+	index []int
+	key   reflect.Type
+	elem  decodeFunc
+}
+
+func (dec *structDecoder) decode(ds *decodeState, v reflect.Value) {
+	for _, fd := range dec.fields {
 		fd.setEmpty(v)
 	}
 	if ds.Type() != MapLen {
@@ -457,43 +642,96 @@ func (dec structDecoder) decode(ds *decodeState, v reflect.Value) {
 	for i := 0; i < n; i++ {
 		// Key
 		ds.unpack()
-		var fd *fieldDec
+		var (
+			fd   *fieldDec
+			key  string
+			rest bool
+		)
 		if ds.Type() == String || ds.Type() == Binary {
-			fd = dec[string(ds.BytesNoCopy())]
+			fd = dec.fields[string(ds.BytesNoCopy())]
+			if fd == nil && dec.fold != nil {
+				fd = dec.fold[strings.ToLower(string(ds.BytesNoCopy()))]
+			}
+			if fd == nil && dec.remain != nil {
+				key, rest = ds.String(), true
+			} else if fd == nil && ds.disallowUnknownFields {
+				ds.saveError(&UnknownFieldError{Field: ds.String(), Type: v.Type()})
+			}
 		} else {
 			ds.saveErrorAndSkip(reflect.ValueOf(""), nil)
 		}
 
 		// Value
 		ds.unpack()
-		if fd != nil {
-			fv := fieldByIndex(v, fd.index)
+		switch {
+		case fd != nil:
+			fv := fieldByIndexAlloc(v, fd.index)
+			ds.pushPath(pathElem{name: fd.name})
 			fd.f(ds, fv)
-		} else {
+			ds.popPath()
+		case rest:
+			dec.remain.decode(ds, v, key)
+		default:
 			ds.skip()
 		}
 	}
 }
 
+func (rd *remainDec) decode(ds *decodeState, v reflect.Value, key string) {
+	m := fieldByIndexAlloc(v, rd.index)
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	kv := reflect.ValueOf(key).Convert(rd.key)
+	elem := reflect.New(m.Type().Elem()).Elem()
+	ds.pushPath(pathElem{name: rd.name})
+	ds.pushPath(pathElem{key: kv})
+	rd.elem(ds, elem)
+	ds.popPath()
+	ds.popPath()
+	m.SetMapIndex(kv, elem)
+}
+
 func (b *decodeBuilder) structDecoder(t reflect.Type) decodeFunc {
-	fields, array := fieldsForType(t)
+	fields, remain, array := fieldsForType(t, b.opts)
 	if array {
 		var dec structArrayDecoder
 		for _, field := range fields {
 			dec = append(dec, &fieldDec{
+				name:  field.name,
 				index: field.index,
 				f:     decoderForType(field.typ, b),
 			})
 		}
 		return dec.decode
 	}
-	dec := make(structDecoder)
+	dec := &structDecoder{fields: make(map[string]*fieldDec)}
+	if b.opts.CaseInsensitive {
+		dec.fold = make(map[string]*fieldDec)
+	}
 	for _, field := range fields {
-		dec[field.name] = &fieldDec{
+		fd := &fieldDec{
+			name:  field.name,
 			index: field.index,
 			f:     decoderForType(field.typ, b),
 			empty: field.empty,
 		}
+		dec.fields[field.name] = fd
+		if dec.fold != nil {
+			// The first field wins when field names differ only in
+			// case.
+			if k := strings.ToLower(field.name); dec.fold[k] == nil {
+				dec.fold[k] = fd
+			}
+		}
+	}
+	if remain != nil {
+		dec.remain = &remainDec{
+			name:  remain.name,
+			index: remain.index,
+			key:   remain.typ.Key(),
+			elem:  decoderForType(remain.typ.Elem(), b),
+		}
 	}
 	return dec.decode
 }
@@ -533,15 +771,12 @@ func unmarshalDecoder(ds *decodeState, v reflect.Value) {
 	if v.IsNil() {
 		v.Set(reflect.New(v.Type().Elem()))
 	}
+	callUnmarshaler(ds, v)
+}
+
+func callUnmarshaler(ds *decodeState, v reflect.Value) {
 	m := v.Interface().(Unmarshaler)
-	err := m.UnmarshalMsgPack(ds.Decoder)
-	if e, ok := err.(*DecodeConvertError); ok {
-		if ds.errSaved != nil {
-			ds.errSaved = e
-		}
-	} else if err != nil {
-		abort(err)
-	}
+	ds.saveError(m.UnmarshalMsgPack(ds.Decoder))
 }
 
 type unmarshalAddrDecoder struct{ f decodeFunc }
@@ -551,7 +786,62 @@ func (dec unmarshalAddrDecoder) decode(ds *decodeState, v reflect.Value) {
 		dec.f(ds, v)
 		return
 	}
-	unmarshalDecoder(ds, v.Addr())
+	// Unmarshalers for non-pointer values handle Nil themselves.
+	callUnmarshaler(ds, v.Addr())
+}
+
+var (
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func unmarshalBinary(p reflect.Value, data []byte) error {
+	return p.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(data)
+}
+
+func unmarshalText(p reflect.Value, data []byte) error {
+	return p.Interface().(encoding.TextUnmarshaler).UnmarshalText(data)
+}
+
+// byteUnmarshalDecoder decodes String and Binary values to pointers with the
+// UnmarshalBinary or UnmarshalText method.
+type byteUnmarshalDecoder func(p reflect.Value, data []byte) error
+
+func (unmarshal byteUnmarshalDecoder) decode(ds *decodeState, v reflect.Value) {
+	switch ds.Type() {
+	case Nil:
+		v.Set(reflect.Zero(v.Type()))
+	case String, Binary:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		ds.saveError(unmarshal(v, ds.Bytes()))
+	default:
+		ds.saveErrorAndSkip(v, nil)
+	}
+}
+
+// byteUnmarshalAddrDecoder decodes String and Binary values to addressable
+// values with the UnmarshalBinary or UnmarshalText method of the pointer to
+// the value. Other values are decoded with f.
+type byteUnmarshalAddrDecoder struct {
+	f         decodeFunc
+	unmarshal func(p reflect.Value, data []byte) error
+}
+
+func (dec byteUnmarshalAddrDecoder) decode(ds *decodeState, v reflect.Value) {
+	if !v.CanAddr() {
+		dec.f(ds, v)
+		return
+	}
+	switch ds.Type() {
+	case Nil:
+		v.Set(reflect.Zero(v.Type()))
+	case String, Binary:
+		ds.saveError(dec.unmarshal(v.Addr(), ds.Bytes()))
+	default:
+		ds.saveErrorAndSkip(v, nil)
+	}
 }
 
 type extensionValue struct {
@@ -566,10 +856,27 @@ func (ev extensionValue) MarshalMsgPack(e *Encoder) error {
 func decodeNoReflect(ds *decodeState) (x interface{}) {
 	switch ds.Type() {
 	case Int:
+		if ds.useNumber {
+			return ds.number()
+		}
 		return ds.Int()
 	case Uint:
+		if ds.useNumber {
+			return ds.number()
+		}
+		if ds.useInt64 {
+			n := ds.Uint()
+			if int64(n) < 0 {
+				ds.saveError(&DecodeConvertError{SrcType: Uint, SrcValue: n, DestType: intTypes[64]})
+				return nil
+			}
+			return int64(n)
+		}
 		return ds.Uint()
 	case Float:
+		if ds.useNumber {
+			return ds.number()
+		}
 		return ds.Float()
 	case Bool:
 		return ds.Bool()
@@ -578,16 +885,25 @@ func decodeNoReflect(ds *decodeState) (x interface{}) {
 	case String:
 		return ds.String()
 	case Binary:
+		if ds.binaryAsString {
+			return ds.String()
+		}
 		return ds.Bytes()
 	case ArrayLen:
 		n := ds.Len()
 		a := make([]interface{}, n)
+		ds.pushPath(pathElem{})
 		for i := 0; i < n; i++ {
+			ds.path[len(ds.path)-1].index = i
 			ds.unpack()
 			a[i] = decodeNoReflect(ds)
 		}
+		ds.popPath()
 		return a
 	case MapLen:
+		if ds.mapType != nil && ds.mapType.Key() == emptyInterfaceType {
+			return decodeInterfaceKeyMap(ds)
+		}
 		n := ds.Len()
 		m := make(map[string]interface{})
 		for i := 0; i < n; i++ {
@@ -600,27 +916,70 @@ func decodeNoReflect(ds *decodeState) (x interface{}) {
 			}
 			key := ds.String()
 			ds.unpack()
+			ds.pushPath(pathElem{key: reflect.ValueOf(key)})
 			m[key] = decodeNoReflect(ds)
+			ds.popPath()
+		}
+		if ds.mapType != nil {
+			return reflect.ValueOf(m).Convert(ds.mapType).Interface()
 		}
 		return m
 	case Extension:
 		if f := ds.extensions[ds.Extension()]; f != nil {
 			v, err := f(ds.Bytes())
-			if e, ok := err.(*DecodeConvertError); ok {
-				if ds.errSaved != nil {
-					ds.errSaved = e
-				}
-			} else if err != nil {
-				abort(err)
-			}
+			ds.saveError(err)
 			return v
 		}
+		if ext := ds.extensionForKind(); ext != nil {
+			v, err := ext.Decode(ds.Bytes())
+			ds.saveError(err)
+			return v
+		}
+		if ds.IsTimestamp() {
+			if t, err := ds.Timestamp(); err == nil {
+				return t
+			}
+		}
 		return extensionValue{ds.Extension(), ds.Bytes()}
 	default:
 		return nil
 	}
 }
 
+// decodeInterfaceKeyMap decodes the current map to a map with interface{}
+// keys.
+func decodeInterfaceKeyMap(ds *decodeState) interface{} {
+	n := ds.Len()
+	m := make(map[interface{}]interface{})
+	for i := 0; i < n; i++ {
+		ds.unpack()
+		var key interface{}
+		ok := ds.Type() != ArrayLen && ds.Type() != MapLen
+		if ds.Type() == String || ds.Type() == Binary {
+			// Binary keys decode to []byte, which is not comparable.
+			key = ds.String()
+		} else if ok {
+			key = decodeNoReflect(ds)
+			ok = key == nil || reflect.TypeOf(key).Comparable()
+		}
+		if !ok {
+			ds.saveErrorAndSkip(reflect.New(emptyInterfaceType).Elem(), nil)
+			ds.unpack()
+			ds.skip()
+			continue
+		}
+		pk := reflect.ValueOf(key)
+		if !pk.IsValid() {
+			pk = reflect.ValueOf(&key).Elem()
+		}
+		ds.unpack()
+		ds.pushPath(pathElem{key: pk})
+		m[key] = decodeNoReflect(ds)
+		ds.popPath()
+	}
+	return reflect.ValueOf(m).Convert(ds.mapType).Interface()
+}
+
 // DecodeConvertError describes a MessagePack value that was not appropriate
 // for a value of a specific Go type.
 type DecodeConvertError struct {
@@ -630,13 +989,41 @@ type DecodeConvertError struct {
 	SrcValue interface{}
 	// Type of the Go value that could not be assigned to.
 	DestType reflect.Type
+	// Path to the value from the top-level value, for example
+	// ".functions[12].parameters[0].Type". The path is empty for the
+	// top-level value.
+	Path string
+	// Offset of the value in the input stream.
+	Offset int64
 }
 
 func (e *DecodeConvertError) Error() string {
+	var s string
 	if e.SrcValue == nil {
-		return fmt.Sprintf("msgpack: cannot convert %s to %s", e.SrcType, e.DestType)
+		s = fmt.Sprintf("msgpack: cannot convert %s to %s", e.SrcType, e.DestType)
+	} else {
+		s = fmt.Sprintf("msgpack: cannot convert %s(%v) to %s", e.SrcType, e.SrcValue, e.DestType)
 	}
-	return fmt.Sprintf("msgpack: cannot convert %s(%v) to %s", e.SrcType, e.SrcValue, e.DestType)
+	if e.Path != "" {
+		s += " at " + e.Path
+	}
+	if e.Path != "" || e.Offset != 0 {
+		s += fmt.Sprintf(" (offset %d)", e.Offset)
+	}
+	return s
+}
+
+// UnknownFieldError describes a MessagePack map key that does not match a
+// field in the destination struct.
+type UnknownFieldError struct {
+	// The map key.
+	Field string
+	// Type of the struct.
+	Type reflect.Type
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("msgpack: unknown field %q for type %s", e.Field, e.Type)
 }
 
 func decodeUnsupportedType(ds *decodeState, v reflect.Value) {
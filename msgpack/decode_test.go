@@ -3,8 +3,11 @@ package msgpack
 import (
 	"bytes"
 	"io"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 type testDecStruct struct {
@@ -107,6 +110,13 @@ var decodeTests = []struct {
 	{func() interface{} { return new(interface{}) }, []interface{}{extension{1, "hello"}}, testExtension1{[]byte("hello")}},
 	{func() interface{} { return new(testExtension1) }, []interface{}{extension{1, "hello"}}, testExtension1{[]byte("hello")}},
 
+	// Timestamp
+	{func() interface{} { return new(time.Time) }, []interface{}{time.Unix(1, 0)}, time.Unix(1, 0)},
+	{func() interface{} { return new(time.Time) }, []interface{}{time.Unix(1, 1)}, time.Unix(1, 1)},
+	{func() interface{} { return new(time.Time) }, []interface{}{time.Unix(-1, 1)}, time.Unix(-1, 1)},
+	{func() interface{} { return new(time.Time) }, []interface{}{nil}, time.Time{}},
+	{func() interface{} { return new(interface{}) }, []interface{}{time.Unix(1<<34, 2)}, time.Unix(1<<34, 2)},
+
 	// Empty
 	{func() interface{} { return &testDecEmptyStruct{} }, []interface{}{mapLen(0)}, testDecEmptyStruct{B: true, S: "blank", N: 1234, N8: 45, N32: 6789}},
 	{func() interface{} { return &testDecEmptyStruct{} }, []interface{}{mapLen(1), "S", "not blank"}, testDecEmptyStruct{B: true, S: "not blank", N: 1234, N8: 45, N32: 6789}},
@@ -154,3 +164,361 @@ func TestDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshal(t *testing.T) {
+	for _, tt := range decodeTests {
+		data, err := pack(tt.data...)
+		if err != nil {
+			t.Errorf("pack(%+v) returned error %v", tt.data, err)
+			continue
+		}
+		if len(tt.data) > 0 {
+			if _, ok := tt.data[0].(extension); ok {
+				// Unmarshal does not support extension maps.
+				continue
+			}
+		}
+		arg := tt.arg()
+		if err := Unmarshal(data, arg); err != nil {
+			t.Errorf("Unmarshal(%+v, %T) returned error %v", tt.data, arg, err)
+			continue
+		}
+		rv := reflect.ValueOf(arg)
+		if rv.Kind() == reflect.Ptr {
+			rv = rv.Elem()
+		}
+		if v := rv.Interface(); !reflect.DeepEqual(v, tt.expected) {
+			t.Errorf("Unmarshal(%+v, %T) returned %#v, want %#v", tt.data, arg, v, tt.expected)
+		}
+	}
+
+	var s string
+	if err := Unmarshal([]byte{0xa1, 'a', 0xc0}, &s); err == nil {
+		t.Error("Unmarshal with trailing data returned nil error")
+	}
+	if err := Unmarshal([]byte{0xa1}, &s); err != io.ErrUnexpectedEOF {
+		t.Errorf("Unmarshal of truncated data returned %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	// Declared lengths larger than the data are rejected before allocating.
+	for _, p := range [][]byte{
+		{0xdd, 0x7f, 0xff, 0xff, 0xff},
+		{0xdf, 0x7f, 0xff, 0xff, 0xff},
+		{0xdb, 0xff, 0xff, 0xff, 0xff},
+	} {
+		var v interface{}
+		if err := Unmarshal(p, &v); err != io.ErrUnexpectedEOF {
+			t.Errorf("Unmarshal(%x) returned %v, want %v", p, err, io.ErrUnexpectedEOF)
+		}
+	}
+	if err := Unmarshal([]byte{0xdd, 0x7f, 0xff, 0xff, 0xff}, &[]int{}); err != io.ErrUnexpectedEOF {
+		t.Errorf("Unmarshal to []int returned %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+var decodeErrorTests = []struct {
+	arg    func() interface{}
+	data   []interface{}
+	strict bool
+	// expected is the expected decoded value.
+	expected interface{}
+	// errType is the expected type of the error or nil for no error.
+	errType interface{}
+}{
+	{func() interface{} { return new(int) }, []interface{}{float64(2)}, false, int(2), nil},
+	{func() interface{} { return new(int) }, []interface{}{float64(2)}, true, int(0), &DecodeConvertError{}},
+	{func() interface{} { return new(int) }, []interface{}{nil}, false, int(0), &DecodeConvertError{}},
+	{func() interface{} { return new(int8) }, []interface{}{uint64(300)}, false, int8(0), &DecodeConvertError{}},
+	{func() interface{} { return new(uint8) }, []interface{}{int64(-1)}, false, uint8(0), &DecodeConvertError{}},
+	{func() interface{} { return new(uint) }, []interface{}{float64(2)}, true, uint(0), &DecodeConvertError{}},
+	{func() interface{} { return new(bool) }, []interface{}{int64(1)}, false, true, nil},
+	{func() interface{} { return new(bool) }, []interface{}{int64(1)}, true, false, &DecodeConvertError{}},
+	{func() interface{} { return new(float32) }, []interface{}{float64(0.1)}, false, float32(0.1), nil},
+	{func() interface{} { return new(float32) }, []interface{}{float64(0.1)}, true, float32(0), &DecodeConvertError{}},
+	{func() interface{} { return new(float32) }, []interface{}{float32(0.1)}, true, float32(0.1), nil},
+	{func() interface{} { return new(float32) }, []interface{}{float64(0.5)}, true, float32(0.5), nil},
+	{func() interface{} { return new(float32) }, []interface{}{float64(1e300)}, false, float32(math.Inf(1)), nil},
+	{func() interface{} { return new(float32) }, []interface{}{float64(1e300)}, true, float32(0), &DecodeConvertError{}},
+	{func() interface{} { return new(float32) }, []interface{}{int64(1 << 24)}, true, float32(1 << 24), nil},
+	{func() interface{} { return new(float32) }, []interface{}{int64(1<<24 + 1)}, false, float32(0), &DecodeConvertError{}},
+	{func() interface{} { return new(float32) }, []interface{}{int64(1<<24 + 1)}, true, float32(0), &DecodeConvertError{}},
+	{func() interface{} { return new(float32) }, []interface{}{int64(-1<<24 - 1)}, true, float32(0), &DecodeConvertError{}},
+	{func() interface{} { return new(float64) }, []interface{}{int64(1 << 53)}, true, float64(1 << 53), nil},
+	{func() interface{} { return new(float64) }, []interface{}{int64(1<<53 + 1)}, true, float64(0), &DecodeConvertError{}},
+	{func() interface{} { return new(float64) }, []interface{}{int64(-1<<53 - 1)}, true, float64(0), &DecodeConvertError{}},
+	{func() interface{} { return new(float64) }, []interface{}{uint64(1<<53 + 1)}, true, float64(0), &DecodeConvertError{}},
+	{func() interface{} { return new(float64) }, []interface{}{int64(math.MaxInt64)}, true, float64(0), &DecodeConvertError{}},
+	{func() interface{} { return new(float64) }, []interface{}{uint64(math.MaxUint64)}, true, float64(0), &DecodeConvertError{}},
+	{func() interface{} { return new(testDecStruct) }, []interface{}{mapLen(3), "B", true, "X", arrayLen(1), int64(1), "S", "s"}, false, testDecStruct{B: true, S: "s"}, nil},
+	{func() interface{} { return new(testDecStruct) }, []interface{}{mapLen(3), "B", true, "X", arrayLen(1), int64(1), "S", "s"}, true, testDecStruct{B: true, S: "s"}, &UnknownFieldError{}},
+}
+
+// nilUnmarshaler records whether UnmarshalMsgPack was called with Nil.
+type nilUnmarshaler struct{ gotNil bool }
+
+func (u *nilUnmarshaler) UnmarshalMsgPack(d *Decoder) error {
+	u.gotNil = d.Type() == Nil
+	return d.Skip()
+}
+
+// convertErrorUnmarshaler returns a conversion error for all values.
+type convertErrorUnmarshaler struct{}
+
+func (u *convertErrorUnmarshaler) UnmarshalMsgPack(d *Decoder) error {
+	if err := d.Skip(); err != nil {
+		return err
+	}
+	return d.ConvertError(reflect.TypeOf(u).Elem())
+}
+
+// structUnmarshaler decodes itself with DecodeStruct.
+type structUnmarshaler struct {
+	A int
+	B string
+}
+
+func (u *structUnmarshaler) UnmarshalMsgPack(d *Decoder) error {
+	return d.DecodeStruct(u)
+}
+
+func TestDecodeStruct(t *testing.T) {
+	data, err := pack(mapLen(3), "a", int64(1), "B", "b", "C", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(bytes.NewReader(data))
+	d.SetFieldOptions(FieldOptions{CaseInsensitive: true})
+	d.DisallowUnknownFields()
+	var u structUnmarshaler
+	if err := d.Decode(&u); err == nil {
+		t.Error("Decode returned nil error, want UnknownFieldError")
+	} else if _, ok := err.(*UnknownFieldError); !ok {
+		t.Errorf("Decode returned %v, want UnknownFieldError", err)
+	}
+	if want := (structUnmarshaler{A: 1, B: "b"}); u != want {
+		t.Errorf("Decode returned %+v, want %+v", u, want)
+	}
+
+	d = NewDecoder(bytes.NewReader(data))
+	if err := d.Unpack(); err != nil {
+		t.Fatal(err)
+	}
+	var i int
+	if err := d.DecodeStruct(&i); err == nil {
+		t.Error("DecodeStruct(*int) returned nil error")
+	}
+	if _, err := d.Peek(); err != io.EOF {
+		t.Errorf("DecodeStruct(*int) did not skip the value, Peek returned %v", err)
+	}
+}
+
+func TestUnmarshalerNilAndErrors(t *testing.T) {
+	// Nil is passed to the UnmarshalMsgPack method of an addressable value.
+	var s struct{ U nilUnmarshaler }
+	if err := Unmarshal([]byte{0x81, 0xa1, 'U', 0xc0}, &s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.U.gotNil {
+		t.Error("UnmarshalMsgPack was not called with Nil")
+	}
+
+	// A conversion error returned by UnmarshalMsgPack is reported after the
+	// remaining fields are decoded.
+	var c struct {
+		C convertErrorUnmarshaler
+		N int
+	}
+	err := Unmarshal([]byte{0x82, 0xa1, 'C', 0x01, 0xa1, 'N', 0x02}, &c)
+	if _, ok := err.(*DecodeConvertError); !ok {
+		t.Errorf("Unmarshal returned error %v, want *DecodeConvertError", err)
+	}
+	if c.N != 2 {
+		t.Errorf("Unmarshal set N to %d, want 2", c.N)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, tt := range decodeErrorTests {
+		data, err := pack(tt.data...)
+		if err != nil {
+			t.Errorf("pack(%+v) returned error %v", tt.data, err)
+			continue
+		}
+		dec := NewDecoder(bytes.NewReader(data))
+		if tt.strict {
+			dec.DisallowUnknownFields()
+			dec.UseStrictNumbers()
+		}
+		arg := tt.arg()
+		err = dec.Decode(arg)
+		if reflect.TypeOf(err) != reflect.TypeOf(tt.errType) {
+			t.Errorf("decode(%+v, %T, strict=%v) returned error %v, want type %T", tt.data, arg, tt.strict, err, tt.errType)
+		}
+		if v := reflect.ValueOf(arg).Elem().Interface(); !reflect.DeepEqual(v, tt.expected) {
+			t.Errorf("decode(%+v, %T, strict=%v) returned %#v, want %#v", tt.data, arg, tt.strict, v, tt.expected)
+		}
+		// Decode should read to EOF.
+		if _, err := dec.r.ReadByte(); err != io.EOF {
+			t.Errorf("decode(%+v, %T) did not read to EOF", tt.data, arg)
+		}
+	}
+}
+
+func TestDecodeErrorPath(t *testing.T) {
+	type parameter struct {
+		Type string
+		Name string
+	}
+	type function struct {
+		Name       string      `msgpack:"name"`
+		Parameters []parameter `msgpack:"parameters"`
+	}
+	type apiInfo struct {
+		Functions []function        `msgpack:"functions"`
+		Types     map[string]int    `msgpack:"types"`
+		Any       []interface{}     `msgpack:"any"`
+		Array     [1]parameter      `msgpack:"array"`
+		Nested    map[string][]bool `msgpack:"nested"`
+	}
+
+	for _, tt := range []struct {
+		data   []interface{}
+		path   string
+		offset int64
+	}{
+		{
+			[]interface{}{mapLen(1), "functions", arrayLen(2),
+				mapLen(1), "name", "a",
+				mapLen(2), "name", "b", "parameters", arrayLen(1), mapLen(1), "Type", int64(1)},
+			".functions[1].parameters[0].Type", 46,
+		},
+		{
+			[]interface{}{mapLen(1), "types", mapLen(2), "a", int64(1), "b", "x"},
+			`.types["b"]`, 13,
+		},
+		{
+			[]interface{}{mapLen(1), "array", arrayLen(1), mapLen(1), "Name", true},
+			".array[0].Name", 14,
+		},
+		{
+			[]interface{}{mapLen(1), "nested", mapLen(1), "k", arrayLen(2), true, "x"},
+			`.nested["k"][1]`, 13,
+		},
+		{
+			[]interface{}{"x"},
+			"", 0,
+		},
+	} {
+		data, err := pack(tt.data...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v apiInfo
+		err = Unmarshal(data, &v)
+		e, ok := err.(*DecodeConvertError)
+		if !ok {
+			t.Errorf("decode(%+v) returned %v, want *DecodeConvertError", tt.data, err)
+			continue
+		}
+		if e.Path != tt.path || e.Offset != tt.offset {
+			t.Errorf("decode(%+v) returned path %q offset %d, want %q %d", tt.data, e.Path, e.Offset, tt.path, tt.offset)
+		}
+		if tt.path != "" && !strings.Contains(e.Error(), tt.path) {
+			t.Errorf("error %q does not contain path %q", e.Error(), tt.path)
+		}
+	}
+}
+
+func TestDecodeInterfaceOptions(t *testing.T) {
+	type stringMap map[string]interface{}
+	type anyMap map[interface{}]interface{}
+
+	for _, tt := range []struct {
+		name     string
+		config   func(*Decoder)
+		data     []interface{}
+		expected interface{}
+	}{
+		{
+			"default",
+			func(*Decoder) {},
+			[]interface{}{arrayLen(4), int64(-1), uint64(1 << 40), 1.5, []byte("b")},
+			[]interface{}{int64(-1), uint64(1 << 40), 1.5, []byte("b")},
+		},
+		{
+			"UseNumber",
+			(*Decoder).UseNumber,
+			[]interface{}{arrayLen(5), int64(-1), uint64(1 << 63), 1.5, 2.0, float32(0.1)},
+			[]interface{}{Number("-1"), Number("9223372036854775808"), Number("1.5"), Number("2.0"), Number("0.1")},
+		},
+		{
+			"UseInt64",
+			(*Decoder).UseInt64,
+			[]interface{}{arrayLen(2), int64(-1), uint64(1 << 40)},
+			[]interface{}{int64(-1), int64(1 << 40)},
+		},
+		{
+			"UseBinaryAsString",
+			(*Decoder).UseBinaryAsString,
+			[]interface{}{mapLen(1), []byte("k"), []byte("v")},
+			map[string]interface{}{"k": "v"},
+		},
+		{
+			"SetMapType string",
+			func(d *Decoder) { d.SetMapType(reflect.TypeOf(stringMap(nil))) },
+			[]interface{}{mapLen(1), "a", mapLen(1), "b", nil},
+			stringMap{"a": stringMap{"b": nil}},
+		},
+		{
+			"SetMapType interface",
+			func(d *Decoder) { d.SetMapType(reflect.TypeOf(anyMap(nil))) },
+			[]interface{}{mapLen(3), int64(1), "x", nil, true, "y", mapLen(0)},
+			anyMap{int64(1): "x", nil: true, "y": anyMap{}},
+		},
+		{
+			"SetMapType interface binary key",
+			func(d *Decoder) { d.SetMapType(reflect.TypeOf(anyMap(nil))) },
+			[]interface{}{mapLen(2), []byte("k"), int64(1), "s", int64(2)},
+			anyMap{"k": int64(1), "s": int64(2)},
+		},
+	} {
+		data, err := pack(tt.data...)
+		if err != nil {
+			t.Fatalf("%s: pack returned error %v", tt.name, err)
+		}
+		dec := NewDecoder(bytes.NewReader(data))
+		tt.config(dec)
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			t.Errorf("%s: decode returned error %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, tt.expected) {
+			t.Errorf("%s: decode returned %#v, want %#v", tt.name, v, tt.expected)
+		}
+	}
+
+	// Errors are reported for values that cannot be stored.
+	for _, tt := range []struct {
+		name   string
+		config func(*Decoder)
+		data   []interface{}
+	}{
+		{"UseInt64 overflow", (*Decoder).UseInt64, []interface{}{arrayLen(1), uint64(1 << 63)}},
+		{"array key", func(d *Decoder) { d.SetMapType(reflect.TypeOf(anyMap(nil))) }, []interface{}{mapLen(1), arrayLen(1), int64(1), "v"}},
+	} {
+		data, err := pack(tt.data...)
+		if err != nil {
+			t.Fatalf("%s: pack returned error %v", tt.name, err)
+		}
+		dec := NewDecoder(bytes.NewReader(data))
+		tt.config(dec)
+		var v interface{}
+		err = dec.Decode(&v)
+		if _, ok := err.(*DecodeConvertError); !ok {
+			t.Errorf("%s: decode returned error %v, want *DecodeConvertError", tt.name, err)
+		}
+		if _, err := dec.r.ReadByte(); err != io.EOF {
+			t.Errorf("%s: decode did not read to EOF", tt.name)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package msgpack
+
+import (
+	"encoding/hex"
+	"io"
+	"strconv"
+	"time"
+)
+
+// DefaultDumpLimits are the limits used by Dump, ToJSON and FromJSON when
+// the limits argument is nil. The limits prevent corrupted or hostile input
+// from causing large allocations or deep recursion. Nesting deeper than 10000
+// levels is not supported with any limits.
+var DefaultDumpLimits = Limits{
+	MaxStringLen:  64 << 20,
+	MaxValueBytes: 256 << 20,
+	MaxDepth:      1000,
+}
+
+// maxDumpDepth is the maximum nesting depth supported by Dump, ToJSON and
+// FromJSON when the limits do not set a smaller MaxDepth.
+const maxDumpDepth = 10000
+
+// dumpLimits returns *limits or DefaultDumpLimits if limits is nil. The
+// returned MaxDepth is at most maxDumpDepth.
+func dumpLimits(limits *Limits) Limits {
+	l := DefaultDumpLimits
+	if limits != nil {
+		l = *limits
+	}
+	if l.MaxDepth <= 0 || l.MaxDepth > maxDumpDepth {
+		l.MaxDepth = maxDumpDepth
+	}
+	return l
+}
+
+// Dump writes a description of each value in the MessagePack stream r to w.
+// Dump writes one line per value. The line contains the byte offset of the
+// value in hexadecimal, the name of the MessagePack format, and the value.
+// Array elements and map entries are indented below the array or map.
+// Extensions are shown with the extension type and data. For example, the
+// stream 82a16101a162c3 is written as:
+//
+//	00000000  fixmap len=2
+//	00000001    fixstr len=1 "a"
+//	00000003    fixint 1
+//	00000004    fixstr len=1 "b"
+//	00000006    true
+//
+// Dump reads r with the specified limits, or with DefaultDumpLimits if limits
+// is nil. Dump returns nil when the end of r is reached after a complete
+// value.
+func Dump(w io.Writer, r io.Reader, limits *Limits) error {
+	d := NewDecoder(r)
+	d.SetLimits(dumpLimits(limits))
+	var line []byte
+	for {
+		depth := d.Depth()
+		if err := d.Unpack(); err != nil {
+			if err == io.EOF {
+				if depth > 0 {
+					return io.ErrUnexpectedEOF
+				}
+				return nil
+			}
+			return err
+		}
+
+		line = line[:0]
+		off := strconv.FormatInt(d.valueOffset, 16)
+		for i := len(off); i < 8; i++ {
+			line = append(line, '0')
+		}
+		line = append(line, off...)
+		line = append(line, ' ', ' ')
+		for i := 0; i < depth; i++ {
+			line = append(line, ' ', ' ')
+		}
+		line = append(line, formatName(d.code)...)
+		line = d.appendDumpValue(line)
+		line = append(line, '\n')
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+}
+
+// appendDumpValue appends the description of the current value to dst.
+func (d *Decoder) appendDumpValue(dst []byte) []byte {
+	switch d.Type() {
+	case Int:
+		dst = append(dst, ' ')
+		dst = strconv.AppendInt(dst, d.Int(), 10)
+	case Uint:
+		dst = append(dst, ' ')
+		dst = strconv.AppendUint(dst, d.Uint(), 10)
+	case Float:
+		bitSize := 64
+		if d.IsFloat32() {
+			bitSize = 32
+		}
+		dst = append(dst, ' ')
+		dst = strconv.AppendFloat(dst, d.Float(), 'g', -1, bitSize)
+	case ArrayLen, MapLen:
+		dst = append(dst, " len="...)
+		dst = strconv.AppendInt(dst, int64(d.Len()), 10)
+	case String:
+		dst = append(dst, " len="...)
+		dst = strconv.AppendInt(dst, int64(len(d.p)), 10)
+		dst = append(dst, ' ')
+		dst = strconv.AppendQuote(dst, string(d.p))
+	case Binary:
+		dst = append(dst, " len="...)
+		dst = strconv.AppendInt(dst, int64(len(d.p)), 10)
+		dst = appendDumpHex(dst, d.p)
+	case Extension:
+		dst = append(dst, " type="...)
+		dst = strconv.AppendInt(dst, int64(int8(d.Extension())), 10)
+		dst = append(dst, " len="...)
+		dst = strconv.AppendInt(dst, int64(len(d.p)), 10)
+		dst = appendDumpHex(dst, d.p)
+		if t, err := d.Timestamp(); err == nil {
+			dst = append(dst, " timestamp="...)
+			dst = t.UTC().AppendFormat(dst, time.RFC3339Nano)
+		}
+	}
+	return dst
+}
+
+func appendDumpHex(dst []byte, p []byte) []byte {
+	if len(p) == 0 {
+		return dst
+	}
+	dst = append(dst, ' ')
+	n := len(dst)
+	dst = append(dst, make([]byte, hex.EncodedLen(len(p)))...)
+	hex.Encode(dst[n:], p)
+	return dst
+}
+
+// formatName returns the name of the MessagePack format with the given code.
+func formatName(code byte) string {
+	switch {
+	case code <= fixIntCodeMax:
+		return "fixint"
+	case code <= fixMapCodeMax:
+		return "fixmap"
+	case code <= fixArrayCodeMax:
+		return "fixarray"
+	case code <= fixStringCodeMax:
+		return "fixstr"
+	case code >= negFixIntCodeMin:
+		return "negfixint"
+	}
+	switch code {
+	case nilCode:
+		return "nil"
+	case falseCode:
+		return "false"
+	case trueCode:
+		return "true"
+	case binary8Code:
+		return "bin8"
+	case binary16Code:
+		return "bin16"
+	case binary32Code:
+		return "bin32"
+	case ext8Code:
+		return "ext8"
+	case ext16Code:
+		return "ext16"
+	case ext32Code:
+		return "ext32"
+	case float32Code:
+		return "float32"
+	case float64Code:
+		return "float64"
+	case uint8Code:
+		return "uint8"
+	case uint16Code:
+		return "uint16"
+	case uint32Code:
+		return "uint32"
+	case uint64Code:
+		return "uint64"
+	case int8Code:
+		return "int8"
+	case int16Code:
+		return "int16"
+	case int32Code:
+		return "int32"
+	case int64Code:
+		return "int64"
+	case fixext1Code:
+		return "fixext1"
+	case fixext2Code:
+		return "fixext2"
+	case fixext4Code:
+		return "fixext4"
+	case fixext8Code:
+		return "fixext8"
+	case fixext16Code:
+		return "fixext16"
+	case string8Code:
+		return "str8"
+	case string16Code:
+		return "str16"
+	case string32Code:
+		return "str32"
+	case array16Code:
+		return "array16"
+	case array32Code:
+		return "array32"
+	case map16Code:
+		return "map16"
+	case map32Code:
+		return "map32"
+	default:
+		return "unused"
+	}
+}
@@ -0,0 +1,73 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	for _, tt := range []struct {
+		h    string
+		want string
+	}{
+		{
+			"82a16101a162c3",
+			"00000000  fixmap len=2\n" +
+				"00000001    fixstr len=1 \"a\"\n" +
+				"00000003    fixint 1\n" +
+				"00000004    fixstr len=1 \"b\"\n" +
+				"00000006    true\n",
+		},
+		{
+			"9392c0ffd9037879" + "7a" + "c4020102" + "cb3ff8000000000000",
+			"00000000  fixarray len=3\n" +
+				"00000001    fixarray len=2\n" +
+				"00000002      nil\n" +
+				"00000003      negfixint -1\n" +
+				"00000004    str8 len=3 \"xyz\"\n" +
+				"00000009    bin8 len=2 0102\n" +
+				"0000000d  float64 1.5\n",
+		},
+		{
+			"d6ff00000001" + "c7030a616263" + "cd0100" + "ca3fc00000",
+			"00000000  fixext4 type=-1 len=4 00000001 timestamp=1970-01-01T00:00:01Z\n" +
+				"00000006  ext8 type=10 len=3 616263\n" +
+				"0000000c  uint16 256\n" +
+				"0000000f  float32 1.5\n",
+		},
+	} {
+		p, _ := hex.DecodeString(tt.h)
+		var buf bytes.Buffer
+		if err := Dump(&buf, bytes.NewReader(p), nil); err != nil {
+			t.Errorf("dump %s returned error %v", tt.h, err)
+			continue
+		}
+		if buf.String() != tt.want {
+			t.Errorf("dump %s returned\n%s\nwant\n%s", tt.h, buf.String(), tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Dump(&buf, bytes.NewReader([]byte{0x92, 0x01}), nil); err != io.ErrUnexpectedEOF {
+		t.Errorf("dump of truncated array returned error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := Dump(&buf, bytes.NewReader([]byte{0xc1}), nil); err == nil {
+		t.Error("dump of unused format returned nil error")
+	}
+
+	// A corrupted header does not cause a large allocation.
+	err := Dump(&buf, bytes.NewReader([]byte{0xdb, 0xff, 0xff, 0xff, 0xff}), nil)
+	if _, ok := err.(*LimitError); !ok {
+		t.Errorf("dump of str32 with huge length returned error %v, want *LimitError", err)
+	}
+	err = Dump(&buf, bytes.NewReader(bytes.Repeat([]byte{0x91}, 2000)), nil)
+	if _, ok := err.(*LimitError); !ok {
+		t.Errorf("dump of deeply nested arrays returned error %v, want *LimitError", err)
+	}
+	err = Dump(&buf, bytes.NewReader([]byte{0xa3, 'a', 'b', 'c'}), &Limits{MaxStringLen: 2})
+	if _, ok := err.(*LimitError); !ok {
+		t.Errorf("dump with MaxStringLen 2 returned error %v, want *LimitError", err)
+	}
+}
@@ -1,25 +1,35 @@
 package msgpack
 
 import (
+	"bytes"
+	"encoding"
 	"reflect"
 	"sync"
+	"time"
 )
 
 // Encode writes the MessagePack encoding of v to the stream.
 //
-// Encode traverses the value v recursively. If an encountered value implements
-// the Marshaler interface Encode calls its MarshalMsgPack method to write the
-// value to the stream.
+// Encode traverses the value v recursively. If an extension is registered for
+// the type of an encountered value, Encode writes the value as that extension.
+// See RegisterExtension. If an encountered value implements the Marshaler
+// interface Encode calls its MarshalMsgPack method to write the value to the
+// stream. If an encountered value implements encoding.BinaryMarshaler, Encode
+// writes the result of its MarshalBinary method as binary. If an encountered
+// value implements encoding.TextMarshaler, Encode writes the result of its
+// MarshalText method as a string.
 //
 // Otherwise, Encode uses the following type-dependent default encodings:
 //
 //  Go Type             MessagePack Type
 //  bool                true or false
-//  float32, float64    float64
+//  float32             float32
+//  float64             float64
 //  string              string
 //  []byte              binary
 //  slices, arrays      array
 //  struct, map         map
+//  time.Time           timestamp extension
 //
 // Struct values encode as maps or arrays. If any struct field tag specifies
 // the "array" option, then the struct is encoded as an array. Otherwise, the
@@ -28,12 +38,27 @@ import (
 //   - the field's tag is "-", or
 //   - the field is empty and its tag specifies the "omitempty" option.
 //
+// The map key for a field is the name in the field's tag or the Go field name
+// if the tag does not specify a name. The encoder's field options can change
+// the map keys and use json field tags. See SetFieldOptions.
+//
 // Anonymous struct fields are marshaled as if their inner exported fields
-// were fields in the outer struct.
+// were fields in the outer struct. The "inline" option marshals a named
+// struct field in the same way.
+//
+// The "remain" option specifies a map field with string keys that holds the
+// map entries without a corresponding struct field. Encode writes the entries
+// of the map after the other fields, except for entries with the same key as
+// another field. A struct can have one remain field, and a struct with a
+// remain field cannot be encoded as an array.
 //
 // The struct field tag "empty" specifies a default value when decoding and the
 // empty value for the "omitempty" option.
 //
+// Map keys that implement encoding.TextMarshaler are encoded as strings using
+// the MarshalText method. Map keys are written in the iteration order of the
+// map unless the encoder sorts map keys. See SetSortMapKeys.
+//
 // Pointer values encode as the value pointed to. A nil pointer encodes as the
 // MessagePack nil value.
 //
@@ -45,32 +70,43 @@ func (e *Encoder) Encode(v interface{}) (err error) {
 	}
 	defer handleAbort(&err)
 	rv := reflect.ValueOf(v)
-	encoderForType(rv.Type(), nil)(e, rv)
+	encoderForType(rv.Type(), &encodeBuilder{opts: e.fieldOptions})(e, rv)
 	return nil
 }
 
+// Marshal returns the MessagePack encoding of v. See the documentation for
+// Encode for details about the conversion of Go values to MessagePack values.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 type encodeFunc func(e *Encoder, v reflect.Value)
 
 type encodeBuilder struct {
-	m map[reflect.Type]encodeFunc
+	m    map[reflect.Type]encodeFunc
+	opts FieldOptions
 }
 
 var encodeFuncCache struct {
 	sync.RWMutex
-	m map[reflect.Type]encodeFunc
+	m map[typeKey]encodeFunc
 }
 
 func encoderForType(t reflect.Type, b *encodeBuilder) encodeFunc {
 	encodeFuncCache.RLock()
-	f, ok := encodeFuncCache.m[t]
+	f, ok := encodeFuncCache.m[typeKey{t, b.opts}]
 	encodeFuncCache.RUnlock()
 	if ok {
 		return f
 	}
 
 	save := false
-	if b == nil {
-		b = &encodeBuilder{m: make(map[reflect.Type]encodeFunc)}
+	if b.m == nil {
+		b.m = make(map[reflect.Type]encodeFunc)
 		save = true
 	} else if f, ok := b.m[t]; ok {
 		return f
@@ -79,16 +115,16 @@ func encoderForType(t reflect.Type, b *encodeBuilder) encodeFunc {
 	b.m[t] = func(e *Encoder, v reflect.Value) {
 		f(e, v)
 	}
-	f = b.encoder(t)
+	f = encoderExtensionEncoder{t, b.encoder(t)}.encode
 	b.m[t] = f
 
 	if save {
 		encodeFuncCache.Lock()
 		if encodeFuncCache.m == nil {
-			encodeFuncCache.m = make(map[reflect.Type]encodeFunc)
+			encodeFuncCache.m = make(map[typeKey]encodeFunc)
 		}
 		for t, f := range b.m {
-			encodeFuncCache.m[t] = f
+			encodeFuncCache.m[typeKey{t, b.opts}] = f
 		}
 		encodeFuncCache.Unlock()
 	}
@@ -96,9 +132,44 @@ func encoderForType(t reflect.Type, b *encodeBuilder) encodeFunc {
 }
 
 func (b *encodeBuilder) encoder(t reflect.Type) encodeFunc {
+	if ext := registeredExtensionForType(t); ext != nil {
+		return extensionEncoder{ext}.encode
+	}
 	if t.Implements(marshalerType) {
 		return b.marshalEncoder(t)
 	}
+	if t == timeType {
+		return timeEncoder
+	}
+	var f encodeFunc
+	switch {
+	case ptrToNativeType(t):
+		f = b.ptrEncoder(t)
+	case t.Implements(binaryMarshalerType):
+		f = binaryMarshalEncoder
+	case t.Implements(textMarshalerType):
+		f = textMarshalEncoder
+	default:
+		f = b.kindEncoder(t)
+	}
+	if t.Kind() != reflect.Ptr {
+		pt := reflect.PtrTo(t)
+		switch {
+		case t.Implements(binaryMarshalerType):
+		case pt.Implements(binaryMarshalerType):
+			f = byteMarshalAddrEncoder{f, binaryMarshalEncoder}.encode
+		case t.Implements(textMarshalerType):
+		case pt.Implements(textMarshalerType):
+			f = byteMarshalAddrEncoder{f, textMarshalEncoder}.encode
+		}
+		if pt.Implements(marshalerType) {
+			f = marshalAddrEncoder{f}.encode
+		}
+	}
+	return f
+}
+
+func (b *encodeBuilder) kindEncoder(t reflect.Type) encodeFunc {
 	var f encodeFunc
 	switch t.Kind() {
 	case reflect.Bool:
@@ -107,7 +178,9 @@ func (b *encodeBuilder) encoder(t reflect.Type) encodeFunc {
 		f = intEncoder
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		f = uintEncoder
-	case reflect.Float32, reflect.Float64:
+	case reflect.Float32:
+		f = float32Encoder
+	case reflect.Float64:
 		f = floatEncoder
 	case reflect.String:
 		f = stringEncoder
@@ -126,9 +199,6 @@ func (b *encodeBuilder) encoder(t reflect.Type) encodeFunc {
 	default:
 		f = encodeUnsupportedType
 	}
-	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(marshalerType) {
-		f = marshalAddrEncoder{f}.encode
-	}
 	return f
 }
 
@@ -162,6 +232,12 @@ func floatEncoder(e *Encoder, v reflect.Value) {
 	}
 }
 
+func float32Encoder(e *Encoder, v reflect.Value) {
+	if err := e.PackFloat32(float32(v.Float())); err != nil {
+		abort(err)
+	}
+}
+
 func stringEncoder(e *Encoder, v reflect.Value) {
 	if err := e.PackString(v.String()); err != nil {
 		abort(err)
@@ -174,13 +250,21 @@ func byteSliceEncoder(e *Encoder, v reflect.Value) {
 	}
 }
 
+var timeType = reflect.TypeOf(time.Time{})
+
+func timeEncoder(e *Encoder, v reflect.Value) {
+	if err := e.PackTimestamp(v.Interface().(time.Time)); err != nil {
+		abort(err)
+	}
+}
+
 func interfaceEncoder(e *Encoder, v reflect.Value) {
 	if !v.IsValid() || v.IsNil() {
 		nilEncoder(e, v)
 		return
 	}
 	v = v.Elem()
-	encoderForType(v.Type(), nil)(e, v)
+	encoderForType(v.Type(), &encodeBuilder{opts: e.fieldOptions})(e, v)
 }
 
 type ptrEncoder struct{ elem encodeFunc }
@@ -207,17 +291,31 @@ func (enc *mapEncoder) encode(e *Encoder, v reflect.Value) {
 	if err := e.PackMapLen(int64(v.Len())); err != nil {
 		abort(err)
 	}
-	for _, k := range v.MapKeys() {
+	keys := v.MapKeys()
+	if e.sortMapKeys {
+		sortMapKeys(e, keys, enc.key)
+	}
+	for _, k := range keys {
 		enc.key(e, k)
 		enc.elem(e, v.MapIndex(k))
 	}
 }
 
 func (b *encodeBuilder) mapEncoder(t reflect.Type) encodeFunc {
-	enc := &mapEncoder{key: encoderForType(t.Key(), b), elem: encoderForType(t.Elem(), b)}
+	enc := &mapEncoder{key: b.mapKeyEncoder(t.Key()), elem: encoderForType(t.Elem(), b)}
 	return enc.encode
 }
 
+// mapKeyEncoder returns the encoder for map keys of type t. Map keys that
+// implement encoding.TextMarshaler are encoded as strings, even if they also
+// implement encoding.BinaryMarshaler.
+func (b *encodeBuilder) mapKeyEncoder(t reflect.Type) encodeFunc {
+	if t != timeType && t.Implements(textMarshalerType) && !t.Implements(marshalerType) && registeredExtensionForType(t) == nil {
+		return encoderExtensionEncoder{t, textMarshalEncoder}.encode
+	}
+	return encoderForType(t, b)
+}
+
 type sliceArrayEncoder struct{ elem encodeFunc }
 
 func (enc sliceArrayEncoder) encodeArray(e *Encoder, v reflect.Value) {
@@ -288,6 +386,62 @@ func (enc marshalAddrEncoder) encode(e *Encoder, v reflect.Value) {
 	enc.f(e, v)
 }
 
+var (
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// ptrToNativeType returns whether t is a pointer to a type that Encode and
+// Decode handle without the encoding.BinaryMarshaler, TextMarshaler and
+// corresponding unmarshaler methods.
+func ptrToNativeType(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr {
+		return false
+	}
+	return t.Elem() == timeType || registeredExtensionForType(t.Elem()) != nil
+}
+
+func binaryMarshalEncoder(e *Encoder, v reflect.Value) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		nilEncoder(e, v)
+		return
+	}
+	data, err := v.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		abort(err)
+	}
+	if err := e.PackBinary(data); err != nil {
+		abort(err)
+	}
+}
+
+func textMarshalEncoder(e *Encoder, v reflect.Value) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		nilEncoder(e, v)
+		return
+	}
+	data, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		abort(err)
+	}
+	if err := e.PackStringBytes(data); err != nil {
+		abort(err)
+	}
+}
+
+// byteMarshalAddrEncoder encodes addressable values with the MarshalBinary or
+// MarshalText method of the pointer to the value. Other values are encoded
+// with f.
+type byteMarshalAddrEncoder struct{ f, m encodeFunc }
+
+func (enc byteMarshalAddrEncoder) encode(e *Encoder, v reflect.Value) {
+	if v.CanAddr() {
+		enc.m(e, v.Addr())
+		return
+	}
+	enc.f(e, v)
+}
+
 type fieldEnc struct {
 	name  string
 	empty func(reflect.Value) bool
@@ -298,6 +452,13 @@ type fieldEnc struct {
 type structEncoder []*fieldEnc
 
 func (enc structEncoder) encode(e *Encoder, v reflect.Value) {
+	if err := e.PackMapLen(enc.numFields(v)); err != nil {
+		abort(err)
+	}
+	enc.encodeFields(e, v)
+}
+
+func (enc structEncoder) numFields(v reflect.Value) int64 {
 	var n int64
 	for _, fe := range enc {
 		fv := fieldByIndex(v, fe.index)
@@ -306,9 +467,10 @@ func (enc structEncoder) encode(e *Encoder, v reflect.Value) {
 		}
 		n++
 	}
-	if err := e.PackMapLen(n); err != nil {
-		abort(err)
-	}
+	return n
+}
+
+func (enc structEncoder) encodeFields(e *Encoder, v reflect.Value) {
 	for _, fe := range enc {
 		fv := fieldByIndex(v, fe.index)
 		if !fv.IsValid() || (fe.empty != nil && fe.empty(fv)) {
@@ -321,6 +483,39 @@ func (enc structEncoder) encode(e *Encoder, v reflect.Value) {
 	}
 }
 
+// remainStructEncoder encodes a struct with a "remain" field. The entries of
+// the remain map are encoded after the fields. Entries with the same key as a
+// field are not encoded.
+type remainStructEncoder struct {
+	fields    structEncoder
+	names     map[string]bool
+	index     []int
+	key, elem encodeFunc
+}
+
+func (enc *remainStructEncoder) encode(e *Encoder, v reflect.Value) {
+	var keys []reflect.Value
+	m := fieldByIndex(v, enc.index)
+	if m.IsValid() {
+		for _, k := range m.MapKeys() {
+			if !enc.names[k.String()] {
+				keys = append(keys, k)
+			}
+		}
+	}
+	if err := e.PackMapLen(enc.fields.numFields(v) + int64(len(keys))); err != nil {
+		abort(err)
+	}
+	enc.fields.encodeFields(e, v)
+	if e.sortMapKeys {
+		sortMapKeys(e, keys, enc.key)
+	}
+	for _, k := range keys {
+		enc.key(e, k)
+		enc.elem(e, m.MapIndex(k))
+	}
+}
+
 func (enc structEncoder) encodeArray(e *Encoder, v reflect.Value) {
 	if err := e.PackArrayLen(int64(len(enc))); err != nil {
 		abort(err)
@@ -332,7 +527,7 @@ func (enc structEncoder) encodeArray(e *Encoder, v reflect.Value) {
 }
 
 func (b *encodeBuilder) structEncoder(t reflect.Type) encodeFunc {
-	fields, array := fieldsForType(t)
+	fields, remain, array := fieldsForType(t, b.opts)
 	enc := make(structEncoder, len(fields))
 	for i, f := range fields {
 		var empty func(reflect.Value) bool
@@ -348,6 +543,19 @@ func (b *encodeBuilder) structEncoder(t reflect.Type) encodeFunc {
 	if array {
 		return enc.encodeArray
 	}
+	if remain != nil {
+		renc := &remainStructEncoder{
+			fields: enc,
+			names:  make(map[string]bool),
+			index:  remain.index,
+			key:    encoderForType(remain.typ.Key(), b),
+			elem:   encoderForType(remain.typ.Elem(), b),
+		}
+		for _, f := range fields {
+			renc.names[f.name] = true
+		}
+		return renc.encode
+	}
 	return enc.encode
 }
 
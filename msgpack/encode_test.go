@@ -2,8 +2,12 @@ package msgpack
 
 import (
 	"bytes"
+	"encoding/hex"
+	"errors"
+	"net"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type renamedString string
@@ -186,3 +190,249 @@ func TestEncode(t *testing.T) {
 		}
 	}
 }
+
+func TestEncodeFloat32(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(struct{ F float32 }{1.5}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(buf.Bytes()), "81a146ca3fc00000"; got != want {
+		t.Errorf("encode float32 field returned %s, want %s", got, want)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	for _, tt := range encodeTests {
+		p, err := Marshal(tt.v)
+		if err != nil {
+			t.Errorf("Marshal(%#v) returned error %v", tt.v, err)
+			continue
+		}
+		data, err := unpack(p)
+		if err != nil {
+			t.Errorf("unpack %#v returned error %v", tt.v, err)
+			continue
+		}
+		if !reflect.DeepEqual(data, tt.data) {
+			t.Errorf("Marshal(%#v)\n\t got: %#v\n\twant: %#v", tt.v, data, tt.data)
+		}
+	}
+}
+
+func TestSortMapKeys(t *testing.T) {
+	for _, tt := range []struct {
+		v interface{}
+		h string
+	}{
+		{map[string]int{"b": 1, "aa": 2, "a": 3}, "83a16103a2616102a16201"},
+		{map[int]bool{10: true, -5: false, 0: true}, "83fbc200c30ac3"},
+		{map[interface{}]interface{}{
+			"x":          1,
+			int64(-1):    nil,
+			uint64(2):    nil,
+			1.5:          nil,
+			true:         nil,
+			nil:          nil,
+			[2]int{1, 2}: nil,
+		}, "87c0c0c3c0ffc0cb3ff8000000000000c002c0a17801920102c0"},
+		{map[string]map[int]int{"a": {2: 0, 1: 0}}, "81a1618201000200"},
+	} {
+		// Encode several times to check that the output does not depend on
+		// map iteration order.
+		for i := 0; i < 10; i++ {
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.SetSortMapKeys(true)
+			if err := e.Encode(tt.v); err != nil {
+				t.Fatalf("encode %#v returned error %v", tt.v, err)
+			}
+			if got := hex.EncodeToString(buf.Bytes()); got != tt.h {
+				t.Errorf("encode %#v returned %s, want %s", tt.v, got, tt.h)
+				break
+			}
+		}
+	}
+}
+
+func TestSortMapKeysEncoderConfig(t *testing.T) {
+	type key struct{ N int8 }
+	type p struct{ XY int }
+	type q struct{ Xa int }
+
+	// Keys are sorted by the encoding written with the encoder's extensions
+	// and field options.
+	for _, tt := range []struct {
+		v      interface{}
+		config func(*Encoder)
+		h      string
+	}{
+		{map[key]int{{1}: 1, {2}: 2}, func(*Encoder) {}, "8281a14e010181a14e0202"},
+		{map[key]int{{1}: 1, {2}: 2}, func(e *Encoder) {
+			e.RegisterExtension(TypeExtension{
+				Type: reflect.TypeOf(key{}),
+				Kind: 10,
+				Encode: func(v interface{}) ([]byte, error) {
+					return []byte{0xff - byte(v.(key).N)}, nil
+				},
+			})
+		}, "82d40afd02d40afe01"},
+		{map[interface{}]int{p{}: 1, q{}: 2}, func(*Encoder) {}, "8281a258590001" + "81a258610002"},
+		{map[interface{}]int{p{}: 1, q{}: 2}, func(e *Encoder) {
+			e.SetFieldOptions(FieldOptions{Naming: SnakeCaseFieldNames})
+		}, "8281a278610002" + "81a278790001"},
+	} {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetSortMapKeys(true)
+		tt.config(e)
+		if err := e.Encode(tt.v); err != nil {
+			t.Fatalf("encode %#v returned error %v", tt.v, err)
+		}
+		if got := hex.EncodeToString(buf.Bytes()); got != tt.h {
+			t.Errorf("encode %#v returned %s, want %s", tt.v, got, tt.h)
+		}
+	}
+}
+
+// binaryText implements the binary and text marshaling interfaces with
+// encodings that differ in the first byte.
+type binaryText struct{ s string }
+
+func (bt binaryText) MarshalBinary() ([]byte, error) { return []byte("b" + bt.s), nil }
+func (bt binaryText) MarshalText() ([]byte, error)   { return []byte("t" + bt.s), nil }
+
+func (bt *binaryText) UnmarshalBinary(p []byte) error {
+	if len(p) == 0 || p[0] != 'b' {
+		return errors.New("bad binary")
+	}
+	bt.s = string(p[1:])
+	return nil
+}
+
+func (bt *binaryText) UnmarshalText(p []byte) error {
+	if len(p) == 0 || p[0] != 't' {
+		return errors.New("bad text")
+	}
+	bt.s = string(p[1:])
+	return nil
+}
+
+func TestBinaryTextMarshaler(t *testing.T) {
+	tm := time.Unix(1, 0)
+	for _, tt := range []struct {
+		v interface{}
+		h string
+	}{
+		{binaryText{"x"}, "c4026278"},
+		{&binaryText{"x"}, "c4026278"},
+		{(*binaryText)(nil), "c0"},
+		{net.IPv4(1, 2, 3, 4), "a7312e322e332e34"},
+		{struct{ IP net.IP }{net.IPv4(1, 2, 3, 4)}, "81a24950a7312e322e332e34"},
+		{map[binaryText]int{{"x"}: 1}, "81a2747801"},
+		{tm, "d6ff00000001"},
+		{&tm, "d6ff00000001"},
+	} {
+		p, err := Marshal(tt.v)
+		if err != nil {
+			t.Fatalf("encode %#v returned error %v", tt.v, err)
+		}
+		if h := hex.EncodeToString(p); h != tt.h {
+			t.Errorf("encode %#v returned %s, want %s", tt.v, h, tt.h)
+			continue
+		}
+
+		v := reflect.New(reflect.TypeOf(tt.v))
+		if err := Unmarshal(p, v.Interface()); err != nil {
+			t.Fatalf("decode %s returned error %v", tt.h, err)
+		}
+		if got := v.Elem().Interface(); !reflect.DeepEqual(got, tt.v) {
+			t.Errorf("decode %s returned %#v, want %#v", tt.h, got, tt.v)
+		}
+	}
+
+	var bt binaryText
+	if err := Unmarshal([]byte{0x01}, &bt); err == nil {
+		t.Error("decode integer to binaryText returned nil error")
+	}
+	if err := Unmarshal([]byte{0xa1, 0x78}, &bt); err == nil || err.Error() != "bad binary" {
+		t.Errorf("decode bad binaryText returned error %v, want bad binary", err)
+	}
+}
+
+func TestInlineRemain(t *testing.T) {
+	type Inner struct {
+		A int
+		B int `msgpack:"b"`
+	}
+	type Ptr struct {
+		Q int
+	}
+	type Outer struct {
+		In    Inner `msgpack:",inline"`
+		P     *Ptr  `msgpack:",inline"`
+		C     string
+		Extra map[string]interface{} `msgpack:",remain"`
+	}
+	type Nested struct {
+		Outer `msgpack:"outer"`
+		D     int
+	}
+
+	for _, tt := range []struct {
+		v interface{}
+		h string
+	}{
+		{Outer{In: Inner{A: 1, B: 2}, C: "c"}, "83a14101a16202a143a163"},
+		{Outer{C: "c", Extra: map[string]interface{}{"z": true, "x": nil, "C": "ignored"}}, "85a14100a16200a143a163a178c0a17ac3"},
+		{Nested{Outer: Outer{C: "c"}, D: 4}, "82a56f75746572" + "83a14100a16200a143a163" + "a14404"},
+	} {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetSortMapKeys(true)
+		if err := enc.Encode(tt.v); err != nil {
+			t.Fatalf("encode %#v returned error %v", tt.v, err)
+		}
+		if h := hex.EncodeToString(buf.Bytes()); h != tt.h {
+			t.Errorf("encode %#v returned %s, want %s", tt.v, h, tt.h)
+		}
+	}
+
+	data, err := pack(mapLen(5), "A", int64(1), "C", "c", "Q", int64(3), "x", int64(2), "y", arrayLen(1), "z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o Outer
+	if err := Unmarshal(data, &o); err != nil {
+		t.Fatal(err)
+	}
+	want := Outer{In: Inner{A: 1}, P: &Ptr{Q: 3}, C: "c", Extra: map[string]interface{}{"x": int64(2), "y": []interface{}{"z"}}}
+	if !reflect.DeepEqual(o, want) {
+		t.Errorf("decode returned %#v, want %#v", o, want)
+	}
+
+	for _, v := range []interface{}{
+		struct {
+			A int `msgpack:",inline"`
+		}{},
+		struct {
+			A map[int]int `msgpack:",remain"`
+		}{},
+		struct {
+			A map[string]int `msgpack:",remain"`
+			B map[string]int `msgpack:",remain"`
+		}{},
+		struct {
+			A int            `msgpack:",array"`
+			B map[string]int `msgpack:",remain"`
+		}{},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("encode %T did not panic", v)
+				}
+			}()
+			Marshal(v)
+		}()
+	}
+}
@@ -0,0 +1,223 @@
+package msgpack
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TypeExtension specifies functions for encoding and decoding values of a Go
+// type as a MessagePack extension. Use a TypeExtension to encode and decode
+// types that do not implement the Marshaler and Unmarshaler interfaces, such
+// as types from other packages.
+type TypeExtension struct {
+	// Type is the Go type.
+	Type reflect.Type
+
+	// Kind is the MessagePack extension type in the range -128 to 127.
+	Kind int
+
+	// Encode returns the extension data for v. The value v has type Type.
+	// Nil pointer, map, slice and interface values are encoded as the
+	// MessagePack nil value without calling Encode.
+	Encode func(v interface{}) ([]byte, error)
+
+	// Decode returns a value of type Type for the extension data. The
+	// MessagePack nil value is decoded to the zero value of pointer, map,
+	// slice and interface types without calling Decode.
+	Decode func(data []byte) (interface{}, error)
+}
+
+func (ext *TypeExtension) check(needEncode, needDecode bool) {
+	switch {
+	case ext.Type == nil:
+		panic("msgpack: extension type is nil")
+	case ext.Kind < -128 || ext.Kind > 127:
+		panic(fmt.Sprintf("msgpack: extension kind %d out of range", ext.Kind))
+	case needEncode && ext.Encode == nil:
+		panic("msgpack: extension for " + ext.Type.String() + " has nil Encode function")
+	case needDecode && ext.Decode == nil:
+		panic("msgpack: extension for " + ext.Type.String() + " has nil Decode function")
+	}
+}
+
+var extensionRegistry struct {
+	sync.RWMutex
+	byType map[reflect.Type]*TypeExtension
+	byKind map[int]*TypeExtension
+}
+
+// RegisterExtension registers ext for all encoders and decoders. Extensions
+// registered with Encoder.RegisterExtension and Decoder.RegisterExtension
+// take precedence over extensions registered with this function.
+// RegisterExtension is typically called from an init function.
+//
+// RegisterExtension panics if ext.Type or ext.Kind is already registered or
+// if ext.Kind is -1, the extension type of the MessagePack timestamp.
+func RegisterExtension(ext TypeExtension) {
+	ext.check(true, true)
+	if ext.Kind == timestampExtension {
+		panic("msgpack: extension kind -1 is reserved for timestamps")
+	}
+
+	extensionRegistry.Lock()
+	if extensionRegistry.byType == nil {
+		extensionRegistry.byType = make(map[reflect.Type]*TypeExtension)
+		extensionRegistry.byKind = make(map[int]*TypeExtension)
+	}
+	if _, ok := extensionRegistry.byType[ext.Type]; ok {
+		extensionRegistry.Unlock()
+		panic("msgpack: extension for " + ext.Type.String() + " registered twice")
+	}
+	if _, ok := extensionRegistry.byKind[ext.Kind]; ok {
+		extensionRegistry.Unlock()
+		panic(fmt.Sprintf("msgpack: extension kind %d registered twice", ext.Kind))
+	}
+	extensionRegistry.byType[ext.Type] = &ext
+	extensionRegistry.byKind[ext.Kind] = &ext
+	extensionRegistry.Unlock()
+
+	// Discard the encoders and decoders built before the extension was
+	// registered.
+	encodeFuncCache.Lock()
+	encodeFuncCache.m = nil
+	encodeFuncCache.Unlock()
+	decodeFuncCache.Lock()
+	decodeFuncCache.m = nil
+	decodeFuncCache.Unlock()
+	structDecodeFuncCache.Lock()
+	structDecodeFuncCache.m = nil
+	structDecodeFuncCache.Unlock()
+}
+
+func registeredExtensionForType(t reflect.Type) *TypeExtension {
+	extensionRegistry.RLock()
+	ext := extensionRegistry.byType[t]
+	extensionRegistry.RUnlock()
+	return ext
+}
+
+func registeredExtensionForKind(kind int) *TypeExtension {
+	extensionRegistry.RLock()
+	ext := extensionRegistry.byKind[kind]
+	extensionRegistry.RUnlock()
+	return ext
+}
+
+// RegisterExtension registers ext for the encoder. The Decode function in
+// ext is not used.
+func (e *Encoder) RegisterExtension(ext TypeExtension) {
+	ext.check(true, false)
+	if e.extensions == nil {
+		e.extensions = make(map[reflect.Type]*TypeExtension)
+	}
+	e.extensions[ext.Type] = &ext
+}
+
+// RegisterExtension registers ext for the decoder. The decoder uses ext to
+// decode values of type ext.Type and to decode extensions of kind ext.Kind to
+// interface values. The Encode function in ext is not used.
+func (d *Decoder) RegisterExtension(ext TypeExtension) {
+	ext.check(false, true)
+	if d.typeExtensions == nil {
+		d.typeExtensions = make(map[reflect.Type]*TypeExtension)
+		d.kindExtensions = make(map[int]*TypeExtension)
+	}
+	d.typeExtensions[ext.Type] = &ext
+	d.kindExtensions[ext.Kind] = &ext
+}
+
+// extensionForKind returns the extension registered for the current
+// Extension value.
+func (d *Decoder) extensionForKind() *TypeExtension {
+	kind := int(int8(d.Extension()))
+	if ext := d.kindExtensions[kind]; ext != nil {
+		return ext
+	}
+	return registeredExtensionForKind(kind)
+}
+
+func nilable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+type extensionEncoder struct{ ext *TypeExtension }
+
+func (enc extensionEncoder) encode(e *Encoder, v reflect.Value) {
+	if nilable(v.Kind()) && v.IsNil() {
+		nilEncoder(e, v)
+		return
+	}
+	data, err := enc.ext.Encode(v.Interface())
+	if err != nil {
+		abort(err)
+	}
+	if err := e.PackExtension(enc.ext.Kind, data); err != nil {
+		abort(err)
+	}
+}
+
+// encoderExtensionEncoder uses an extension registered with the encoder to
+// encode values of type t. Other encoders use f.
+type encoderExtensionEncoder struct {
+	t reflect.Type
+	f encodeFunc
+}
+
+func (enc encoderExtensionEncoder) encode(e *Encoder, v reflect.Value) {
+	if e.extensions != nil {
+		if ext := e.extensions[enc.t]; ext != nil {
+			extensionEncoder{ext}.encode(e, v)
+			return
+		}
+	}
+	enc.f(e, v)
+}
+
+type extensionDecoder struct{ ext *TypeExtension }
+
+func (dec extensionDecoder) decode(ds *decodeState, v reflect.Value) {
+	switch {
+	case ds.Type() == Nil && nilable(v.Kind()):
+		v.Set(reflect.Zero(v.Type()))
+	case ds.Type() == Extension && int8(ds.Extension()) == int8(dec.ext.Kind):
+		x, err := dec.ext.Decode(ds.Bytes())
+		if err != nil {
+			ds.saveError(err)
+			return
+		}
+		xv := reflect.ValueOf(x)
+		switch {
+		case !xv.IsValid():
+			v.Set(reflect.Zero(v.Type()))
+		case xv.Type().AssignableTo(v.Type()):
+			v.Set(xv)
+		default:
+			abort(fmt.Errorf("msgpack: extension for %s decoded value of type %s", v.Type(), xv.Type()))
+		}
+	default:
+		ds.saveErrorAndSkip(v, nil)
+	}
+}
+
+// decoderExtensionDecoder uses an extension registered with the decoder to
+// decode values of type t. Other decoders use f.
+type decoderExtensionDecoder struct {
+	t reflect.Type
+	f decodeFunc
+}
+
+func (dec decoderExtensionDecoder) decode(ds *decodeState, v reflect.Value) {
+	if ds.typeExtensions != nil {
+		if ext := ds.typeExtensions[dec.t]; ext != nil {
+			extensionDecoder{ext}.decode(ds, v)
+			return
+		}
+	}
+	dec.f(ds, v)
+}
@@ -0,0 +1,169 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+type extPoint struct{ X, Y int8 }
+
+func init() {
+	RegisterExtension(TypeExtension{
+		Type: reflect.TypeOf(extPoint{}),
+		Kind: 10,
+		Encode: func(v interface{}) ([]byte, error) {
+			p := v.(extPoint)
+			return []byte{byte(p.X), byte(p.Y)}, nil
+		},
+		Decode: func(data []byte) (interface{}, error) {
+			if len(data) != 2 {
+				return nil, errors.New("bad point")
+			}
+			return extPoint{int8(data[0]), int8(data[1])}, nil
+		},
+	})
+}
+
+var bigIntExtension = TypeExtension{
+	Type: reflect.TypeOf((*big.Int)(nil)),
+	Kind: -10,
+	Encode: func(v interface{}) ([]byte, error) {
+		return v.(*big.Int).GobEncode()
+	},
+	Decode: func(data []byte) (interface{}, error) {
+		x := new(big.Int)
+		return x, x.GobDecode(data)
+	},
+}
+
+func TestRegisterExtension(t *testing.T) {
+	type S struct {
+		P  extPoint
+		PP *extPoint
+	}
+
+	for _, tt := range []struct {
+		v interface{}
+		h string
+	}{
+		{extPoint{1, -1}, "d50a01ff"},
+		{&extPoint{1, 2}, "d50a0102"},
+		{[]extPoint{{1, 2}}, "91d50a0102"},
+		{S{P: extPoint{3, 4}}, "82a150d50a0304a25050c0"},
+	} {
+		p, err := Marshal(tt.v)
+		if err != nil {
+			t.Fatalf("encode %#v returned error %v", tt.v, err)
+		}
+		if h := hex.EncodeToString(p); h != tt.h {
+			t.Errorf("encode %#v returned %s, want %s", tt.v, h, tt.h)
+		}
+
+		v := reflect.New(reflect.TypeOf(tt.v))
+		if err := Unmarshal(p, v.Interface()); err != nil {
+			t.Fatalf("decode %s returned error %v", tt.h, err)
+		}
+		if !reflect.DeepEqual(v.Elem().Interface(), tt.v) {
+			t.Errorf("decode %s returned %#v, want %#v", tt.h, v.Elem().Interface(), tt.v)
+		}
+	}
+
+	var x interface{}
+	if err := Unmarshal([]byte{0xd5, 0x0a, 0x01, 0x02}, &x); err != nil {
+		t.Fatal(err)
+	}
+	if x != (extPoint{1, 2}) {
+		t.Errorf("decode to interface returned %#v, want %#v", x, extPoint{1, 2})
+	}
+
+	var p extPoint
+	err := Unmarshal([]byte{0xa1, 0x78}, &p)
+	if _, ok := err.(*DecodeConvertError); !ok {
+		t.Errorf("decode string to extension type returned error %v, want *DecodeConvertError", err)
+	}
+	err = Unmarshal([]byte{0xd4, 0x0a, 0x01}, &p)
+	if err == nil || err.Error() != "bad point" {
+		t.Errorf("decode short point returned error %v, want bad point", err)
+	}
+}
+
+
+func TestRegisterExtensionPanics(t *testing.T) {
+	type other struct{}
+	encode := func(v interface{}) ([]byte, error) { return nil, nil }
+	decode := func(data []byte) (interface{}, error) { return nil, nil }
+	for _, ext := range []TypeExtension{
+		{Type: reflect.TypeOf(extPoint{}), Kind: 11, Encode: encode, Decode: decode},
+		{Type: reflect.TypeOf(other{}), Kind: 10, Encode: encode, Decode: decode},
+		{Type: reflect.TypeOf(other{}), Kind: -1, Encode: encode, Decode: decode},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterExtension(%v, %d) did not panic", ext.Type, ext.Kind)
+				}
+			}()
+			RegisterExtension(ext)
+		}()
+	}
+}
+func TestEncoderDecoderExtension(t *testing.T) {
+	type S struct {
+		N *big.Int
+	}
+	n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.RegisterExtension(bigIntExtension)
+	if err := enc.Encode(S{n}); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(S{}); err != nil {
+		t.Fatal(err)
+	}
+	p := buf.Bytes()
+
+	// An encoder without the extension uses the default encoding.
+	q, err := Marshal(S{n})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(p[:len(q)], q) {
+		t.Errorf("encoder without extension used the extension")
+	}
+
+	dec := NewDecoder(bytes.NewReader(p))
+	dec.RegisterExtension(bigIntExtension)
+	var s S
+	if err := dec.Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.N == nil || s.N.Cmp(n) != 0 {
+		t.Errorf("decode returned %v, want %v", s.N, n)
+	}
+	if err := dec.Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.N != nil {
+		t.Errorf("decode nil returned %v, want nil", s.N)
+	}
+
+	var x interface{}
+	dec = NewDecoder(bytes.NewReader(p))
+	dec.RegisterExtension(bigIntExtension)
+	if err := dec.Decode(&x); err != nil {
+		t.Fatal(err)
+	}
+	m, ok := x.(map[string]interface{})
+	if !ok {
+		t.Fatalf("decode to interface returned %#v, want map", x)
+	}
+	if got, ok := m["N"].(*big.Int); !ok || got.Cmp(n) != 0 {
+		t.Errorf("decode to interface returned %#v, want %v", m["N"], n)
+	}
+}
@@ -0,0 +1,324 @@
+package msgpack
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// ToJSON converts each value in the MessagePack stream r to JSON and writes
+// the JSON to w, one value per line. The conversion is:
+//
+//	Nil                    null
+//	Bool                   true or false
+//	Int, Uint, Float       number; Float values contain a decimal point or an exponent
+//	String                 string; invalid UTF-8 is replaced with U+FFFD
+//	Binary                 string containing the base64 encoding of the data
+//	ArrayLen               array
+//	MapLen                 object
+//	timestamp Extension    string containing the time in RFC 3339 format
+//	other Extension        object {"type": <extension type>, "data": <base64 data>}
+//
+// Map keys are converted to JSON strings. Map keys that are arrays, maps or
+// extensions other than timestamps are not supported. NaN and infinite
+// floats are not supported.
+//
+// ToJSON reads r with the specified limits, or with DefaultDumpLimits if
+// limits is nil.
+func ToJSON(w io.Writer, r io.Reader, limits *Limits) error {
+	d := NewDecoder(r)
+	d.SetLimits(dumpLimits(limits))
+	var buf []byte
+	for {
+		if err := d.Unpack(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		var err error
+		buf, err = d.appendJSON(buf[:0])
+		if err != nil {
+			return err
+		}
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+}
+
+// appendJSON appends the JSON encoding of the current value and any nested
+// values to dst.
+func (d *Decoder) appendJSON(dst []byte) ([]byte, error) {
+	switch d.Type() {
+	case Nil:
+		dst = append(dst, "null"...)
+	case Bool:
+		if d.Bool() {
+			dst = append(dst, "true"...)
+		} else {
+			dst = append(dst, "false"...)
+		}
+	case Int, Uint:
+		dst = append(dst, d.number()...)
+	case Float:
+		if f := d.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return dst, fmt.Errorf("msgpack: cannot convert float %v to JSON", f)
+		}
+		dst = append(dst, d.number()...)
+	case String:
+		dst = appendJSONString(dst, d.p)
+	case Binary:
+		dst = appendJSONBase64(dst, d.p)
+	case Extension:
+		if d.IsTimestamp() {
+			t, err := d.Timestamp()
+			if err != nil {
+				return dst, err
+			}
+			dst = append(dst, '"')
+			dst = t.UTC().AppendFormat(dst, time.RFC3339Nano)
+			dst = append(dst, '"')
+			break
+		}
+		dst = append(dst, `{"type":`...)
+		dst = strconv.AppendInt(dst, int64(int8(d.Extension())), 10)
+		dst = append(dst, `,"data":`...)
+		dst = appendJSONBase64(dst, d.p)
+		dst = append(dst, '}')
+	case ArrayLen:
+		n := d.Len()
+		dst = append(dst, '[')
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if err := d.unpackJSON(); err != nil {
+				return dst, err
+			}
+			var err error
+			if dst, err = d.appendJSON(dst); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, ']')
+	case MapLen:
+		n := d.Len()
+		dst = append(dst, '{')
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if err := d.unpackJSON(); err != nil {
+				return dst, err
+			}
+			var err error
+			if dst, err = d.appendJSONKey(dst); err != nil {
+				return dst, err
+			}
+			dst = append(dst, ':')
+			if err := d.unpackJSON(); err != nil {
+				return dst, err
+			}
+			if dst, err = d.appendJSON(dst); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, '}')
+	}
+	return dst, nil
+}
+
+// appendJSONKey appends the current value as a JSON object key to dst.
+func (d *Decoder) appendJSONKey(dst []byte) ([]byte, error) {
+	switch d.Type() {
+	case String, Binary:
+		return d.appendJSON(dst)
+	case Extension:
+		if d.IsTimestamp() {
+			return d.appendJSON(dst)
+		}
+	case Nil, Bool, Int, Uint, Float:
+		dst = append(dst, '"')
+		dst, err := d.appendJSON(dst)
+		return append(dst, '"'), err
+	}
+	return dst, fmt.Errorf("msgpack: cannot convert map key of type %s to JSON", d.Type())
+}
+
+// unpackJSON reads the next value inside an array or map.
+func (d *Decoder) unpackJSON() error {
+	err := d.Unpack()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func appendJSONBase64(dst []byte, p []byte) []byte {
+	dst = append(dst, '"')
+	n := len(dst)
+	dst = append(dst, make([]byte, base64.StdEncoding.EncodedLen(len(p)))...)
+	base64.StdEncoding.Encode(dst[n:], p)
+	return append(dst, '"')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends p as a JSON string to dst.
+func appendJSONString(dst []byte, p []byte) []byte {
+	dst = append(dst, '"')
+	for len(p) > 0 {
+		r, size := utf8.DecodeRune(p)
+		switch {
+		case r == '"' || r == '\\':
+			dst = append(dst, '\\', byte(r))
+		case r == '\n':
+			dst = append(dst, '\\', 'n')
+		case r == '\r':
+			dst = append(dst, '\\', 'r')
+		case r == '\t':
+			dst = append(dst, '\\', 't')
+		case r < 0x20:
+			dst = append(dst, '\\', 'u', '0', '0', hexDigits[r>>4], hexDigits[r&0xf])
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, `\ufffd`...)
+		default:
+			dst = append(dst, p[:size]...)
+		}
+		p = p[size:]
+	}
+	return append(dst, '"')
+}
+
+// FromJSON converts each value in the JSON stream r to MessagePack and writes
+// the MessagePack to w. JSON numbers are encoded as Int, Uint or Float values
+// as described for Number. JSON objects are encoded as maps with the keys in
+// the order of the JSON object.
+//
+// FromJSON checks the MaxStringLen, MaxArrayLen, MaxMapLen and MaxDepth
+// limits on the JSON values. The limits are the specified limits, or
+// DefaultDumpLimits if limits is nil.
+func FromJSON(w io.Writer, r io.Reader, limits *Limits) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	e := NewEncoder(w)
+	l := dumpLimits(limits)
+	for {
+		v, err := readJSON(dec, &l, 1)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := e.Encode(v); err != nil {
+			return err
+		}
+	}
+}
+
+// jsonObject is a JSON object with the members in the order of the JSON
+// text.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+func (o jsonObject) MarshalMsgPack(e *Encoder) error {
+	if err := e.PackMapLen(int64(len(o))); err != nil {
+		return err
+	}
+	for _, m := range o {
+		if err := e.PackString(m.key); err != nil {
+			return err
+		}
+		if err := e.Encode(m.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readJSON reads the next JSON value from dec. Objects are returned as
+// jsonObject, arrays as []interface{} and numbers as Number. The depth is the
+// nesting depth of the value including the value itself.
+func readJSON(dec *json.Decoder, limits *Limits, depth int) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		if err := checkLimit("MaxDepth", uint64(depth), limits.MaxDepth); err != nil {
+			return nil, err
+		}
+		switch tok {
+		case '[':
+			a := []interface{}{}
+			for dec.More() {
+				v, err := readJSON(dec, limits, depth+1)
+				if err != nil {
+					return nil, unexpectedJSONEOF(err)
+				}
+				a = append(a, v)
+				if err := checkLimit("MaxArrayLen", uint64(len(a)), limits.MaxArrayLen); err != nil {
+					return nil, err
+				}
+			}
+			_, err := dec.Token()
+			return a, unexpectedJSONEOF(err)
+		case '{':
+			o := jsonObject{}
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, unexpectedJSONEOF(err)
+				}
+				key, ok := tok.(string)
+				if !ok {
+					return nil, errors.New("msgpack: invalid JSON object key")
+				}
+				if err := checkLimit("MaxStringLen", uint64(len(key)), limits.MaxStringLen); err != nil {
+					return nil, err
+				}
+				v, err := readJSON(dec, limits, depth+1)
+				if err != nil {
+					return nil, unexpectedJSONEOF(err)
+				}
+				o = append(o, jsonMember{key, v})
+				if err := checkLimit("MaxMapLen", uint64(len(o)), limits.MaxMapLen); err != nil {
+					return nil, err
+				}
+			}
+			_, err := dec.Token()
+			return o, unexpectedJSONEOF(err)
+		default:
+			return nil, fmt.Errorf("msgpack: unexpected JSON delimiter %v", tok)
+		}
+	case json.Number:
+		return Number(tok), nil
+	case string:
+		if err := checkLimit("MaxStringLen", uint64(len(tok)), limits.MaxStringLen); err != nil {
+			return nil, err
+		}
+		return tok, nil
+	default:
+		return tok, nil
+	}
+}
+
+func unexpectedJSONEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
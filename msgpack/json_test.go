@@ -0,0 +1,113 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+var jsonTests = []struct {
+	h    string
+	json string
+}{
+	{"c0", `null`},
+	{"c3", `true`},
+	{"ff", `-1`},
+	{"cf8000000000000000", `9223372036854775808`},
+	{"cb3ff8000000000000", `1.5`},
+	{"cb3ff0000000000000", `1.0`},
+	{"a4613c0a22", `"a<\n\""`},
+	{"90", `[]`},
+	{"80", `{}`},
+	{"92a1780c", `["x",12]`},
+	{"82a16201a16192c0c2", `{"b":1,"a":[null,false]}`},
+}
+
+func TestToJSON(t *testing.T) {
+	for _, tt := range jsonTests {
+		p, _ := hex.DecodeString(tt.h)
+		var buf bytes.Buffer
+		if err := ToJSON(&buf, bytes.NewReader(p), nil); err != nil {
+			t.Errorf("convert %s returned error %v", tt.h, err)
+			continue
+		}
+		if got, want := buf.String(), tt.json+"\n"; got != want {
+			t.Errorf("convert %s returned %s, want %s", tt.h, got, want)
+		}
+	}
+
+	for _, tt := range []struct {
+		h    string
+		json string
+	}{
+		{"c4020102", `"AQI="`},
+		{"a2ff61", `"\ufffda"`},
+		{"d6ff00000001", `"1970-01-01T00:00:01Z"`},
+		{"d5050102", `{"type":5,"data":"AQI="}`},
+		{"8301a178c3a179c0a17a", `{"1":"x","true":"y","null":"z"}`},
+		{"0102", "1\n2"},
+	} {
+		p, _ := hex.DecodeString(tt.h)
+		var buf bytes.Buffer
+		if err := ToJSON(&buf, bytes.NewReader(p), nil); err != nil {
+			t.Errorf("convert %s returned error %v", tt.h, err)
+			continue
+		}
+		if got, want := buf.String(), tt.json+"\n"; got != want {
+			t.Errorf("convert %s returned %s, want %s", tt.h, got, want)
+		}
+	}
+
+	for _, h := range []string{"8190c0", "cb7ff8000000000001", "9201", "dbffffffff", strings.Repeat("91", 2000) + "c0"} {
+		p, _ := hex.DecodeString(h)
+		if err := ToJSON(ioutil.Discard, bytes.NewReader(p), nil); err == nil {
+			t.Errorf("convert %s returned nil error", h)
+		}
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	for _, tt := range jsonTests {
+		var buf bytes.Buffer
+		if err := FromJSON(&buf, strings.NewReader(tt.json), nil); err != nil {
+			t.Errorf("convert %s returned error %v", tt.json, err)
+			continue
+		}
+		if h := hex.EncodeToString(buf.Bytes()); h != tt.h {
+			t.Errorf("convert %s returned %s, want %s", tt.json, h, tt.h)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := FromJSON(&buf, strings.NewReader("1 [2]\n{}"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if h, want := hex.EncodeToString(buf.Bytes()), "01910280"; h != want {
+		t.Errorf("convert stream returned %s, want %s", h, want)
+	}
+
+	for _, s := range []string{`[1,`, `{"a":}`, `1e999`} {
+		if err := FromJSON(&buf, strings.NewReader(s), nil); err == nil {
+			t.Errorf("convert %s returned nil error", s)
+		}
+	}
+
+	for _, tt := range []struct {
+		json   string
+		limits *Limits
+	}{
+		{strings.Repeat("[", 2000) + strings.Repeat("]", 2000), nil},
+		{`"abc"`, &Limits{MaxStringLen: 2}},
+		{`{"abc":1}`, &Limits{MaxStringLen: 2}},
+		{`[1,2,3]`, &Limits{MaxArrayLen: 2}},
+		{`{"a":1,"b":2,"c":3}`, &Limits{MaxMapLen: 2}},
+		{`[[1]]`, &Limits{MaxDepth: 1}},
+	} {
+		err := FromJSON(&buf, strings.NewReader(tt.json), tt.limits)
+		if _, ok := err.(*LimitError); !ok {
+			t.Errorf("convert %.20s returned error %v, want *LimitError", tt.json, err)
+		}
+	}
+}
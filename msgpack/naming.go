@@ -0,0 +1,94 @@
+package msgpack
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FieldNaming specifies the MessagePack map keys for struct fields without a
+// name in the field tag.
+type FieldNaming int
+
+const (
+	// GoFieldNames uses the Go field name as the map key. This is the
+	// default.
+	GoFieldNames FieldNaming = iota
+
+	// SnakeCaseFieldNames uses the Go field name converted to snake case
+	// as the map key. For example, the key for field BufPos is "buf_pos"
+	// and the key for field ChannelID is "channel_id".
+	SnakeCaseFieldNames
+)
+
+// FieldOptions specifies how Encode and Decode match struct fields to
+// MessagePack map keys. The options do not apply to the methods generated by
+// msgpackgen.
+type FieldOptions struct {
+	// Naming specifies the map keys for fields without a name in the field
+	// tag.
+	Naming FieldNaming
+
+	// JSONTags specifies that fields without a msgpack field tag use the
+	// json field tag. The name, "-" and the "omitempty" option in json
+	// field tags have the same meaning as in msgpack field tags. Other
+	// json options are ignored.
+	JSONTags bool
+
+	// CaseInsensitive specifies that Decode matches map keys to struct
+	// fields without regard to case when no field matches the key exactly.
+	// Encode ignores this option.
+	CaseInsensitive bool
+}
+
+// SetFieldOptions sets the options for encoding struct fields.
+func (e *Encoder) SetFieldOptions(opts FieldOptions) {
+	opts.CaseInsensitive = false
+	e.fieldOptions = opts
+}
+
+// SetFieldOptions sets the options for decoding struct fields.
+func (d *Decoder) SetFieldOptions(opts FieldOptions) {
+	d.fieldOptions = opts
+}
+
+// FieldOptions returns the options for decoding struct fields. See
+// SetFieldOptions.
+func (d *Decoder) FieldOptions() FieldOptions {
+	return d.fieldOptions
+}
+
+// typeKey is the key for the encoder and decoder caches.
+type typeKey struct {
+	t    reflect.Type
+	opts FieldOptions
+}
+
+// fieldName returns the map key for the field named name.
+func (opts FieldOptions) fieldName(name string) string {
+	if opts.Naming == SnakeCaseFieldNames {
+		return snakeCase(name)
+	}
+	return name
+}
+
+// snakeCase converts a Go identifier to snake case. An underscore is
+// inserted before an upper case letter that follows a lower case letter or
+// digit, or that is followed by a lower case letter and follows another upper
+// case letter.
+func snakeCase(s string) string {
+	var buf strings.Builder
+	var prev rune
+	for i, r := range s {
+		if unicode.IsUpper(r) && i > 0 && prev != '_' {
+			next, _ := utf8.DecodeRuneInString(s[i+utf8.RuneLen(r):])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && unicode.IsLower(next)) {
+				buf.WriteByte('_')
+			}
+		}
+		buf.WriteRune(unicode.ToLower(r))
+		prev = r
+	}
+	return buf.String()
+}
@@ -0,0 +1,134 @@
+package msgpack
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSnakeCase(t *testing.T) {
+	for _, tt := range []struct{ s, want string }{
+		{"A", "a"},
+		{"BufPos", "buf_pos"},
+		{"ChannelID", "channel_id"},
+		{"HTTPServer", "http_server"},
+		{"ExtNewgrid", "ext_newgrid"},
+		{"Field1", "field1"},
+		{"Field1Name", "field1_name"},
+		{"Snake_Case", "snake_case"},
+		{"ÉtéÀ", "été_à"},
+	} {
+		if got := snakeCase(tt.s); got != tt.want {
+			t.Errorf("snakeCase(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestFieldOptions(t *testing.T) {
+	type S struct {
+		BufPos   int
+		Tagged   int `msgpack:"tagged_name"`
+		JSON     int `json:"json_name,omitempty,string"`
+		Both     int `msgpack:"mp" json:"js"`
+		Skip     int `json:"-"`
+		Dash     int `json:"-,"`
+		Embedded struct {
+			InnerField int
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		opts FieldOptions
+		v    S
+		data []interface{}
+	}{
+		{
+			"default",
+			FieldOptions{},
+			S{BufPos: 1, Tagged: 2, JSON: 3, Both: 4, Skip: 5, Dash: 6},
+			[]interface{}{mapLen(7),
+				"BufPos", int64(1), "tagged_name", int64(2), "JSON", int64(3), "mp", int64(4),
+				"Skip", int64(5), "Dash", int64(6), "Embedded", mapLen(1), "InnerField", int64(0)},
+		},
+		{
+			"snake case",
+			FieldOptions{Naming: SnakeCaseFieldNames},
+			S{BufPos: 1, Tagged: 2},
+			[]interface{}{mapLen(7),
+				"buf_pos", int64(1), "tagged_name", int64(2), "json", int64(0), "mp", int64(0),
+				"skip", int64(0), "dash", int64(0), "embedded", mapLen(1), "inner_field", int64(0)},
+		},
+		{
+			"json tags",
+			FieldOptions{JSONTags: true},
+			S{BufPos: 1, JSON: 3, Both: 4, Dash: 6},
+			[]interface{}{mapLen(6),
+				"BufPos", int64(1), "tagged_name", int64(0), "json_name", int64(3), "mp", int64(4),
+				"-", int64(6), "Embedded", mapLen(1), "InnerField", int64(0)},
+		},
+		{
+			"json tags omitempty",
+			FieldOptions{JSONTags: true, Naming: SnakeCaseFieldNames},
+			S{},
+			[]interface{}{mapLen(5),
+				"buf_pos", int64(0), "tagged_name", int64(0), "mp", int64(0),
+				"-", int64(0), "embedded", mapLen(1), "inner_field", int64(0)},
+		},
+	} {
+		want, err := pack(tt.data...)
+		if err != nil {
+			t.Fatalf("%s: pack returned error %v", tt.name, err)
+		}
+
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetFieldOptions(tt.opts)
+		if err := enc.Encode(tt.v); err != nil {
+			t.Fatalf("%s: encode returned error %v", tt.name, err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("%s: encode returned %x, want %x", tt.name, buf.Bytes(), want)
+		}
+
+		var v S
+		dec := NewDecoder(bytes.NewReader(want))
+		dec.SetFieldOptions(tt.opts)
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("%s: decode returned error %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(v, tt.v) {
+			t.Errorf("%s: decode returned %+v, want %+v", tt.name, v, tt.v)
+		}
+	}
+}
+
+func TestCaseInsensitiveFields(t *testing.T) {
+	type S struct {
+		Name  string
+		NAME  string `msgpack:"NAME"`
+		Other int    `msgpack:"other"`
+	}
+	data, err := pack(mapLen(3), "name", "a", "NAME", "b", "OTHER", int64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v S
+	if err := Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := (S{NAME: "b"}); v != want {
+		t.Errorf("decode returned %+v, want %+v", v, want)
+	}
+
+	v = S{}
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.SetFieldOptions(FieldOptions{CaseInsensitive: true})
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if want := (S{Name: "a", NAME: "b", Other: 1}); v != want {
+		t.Errorf("decode returned %+v, want %+v", v, want)
+	}
+}
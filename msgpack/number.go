@@ -0,0 +1,84 @@
+package msgpack
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Number represents a MessagePack Int, Uint or Float value as a decimal
+// string. A Decoder stores numbers in interface values as Numbers after
+// UseNumber is called. Floating-point numbers always contain a decimal point
+// or an exponent.
+//
+// Number encodes as an Int, Uint or Float value and decodes from these
+// values.
+type Number string
+
+var numberType = reflect.TypeOf(Number(""))
+
+// String returns the literal text of the number.
+func (n Number) String() string { return string(n) }
+
+// Int64 returns the number as an int64.
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// Uint64 returns the number as an uint64.
+func (n Number) Uint64() (uint64, error) {
+	return strconv.ParseUint(string(n), 10, 64)
+}
+
+// Float64 returns the number as a float64.
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// MarshalMsgPack implements the Marshaler interface.
+func (n Number) MarshalMsgPack(e *Encoder) error {
+	if i, err := n.Int64(); err == nil {
+		return e.PackInt(i)
+	}
+	if u, err := n.Uint64(); err == nil {
+		return e.PackUint(u)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return fmt.Errorf("msgpack: invalid number %q", string(n))
+	}
+	return e.PackFloat(f)
+}
+
+// UnmarshalMsgPack implements the Unmarshaler interface.
+func (n *Number) UnmarshalMsgPack(d *Decoder) error {
+	switch d.Type() {
+	case Int, Uint, Float:
+		*n = d.number()
+		return nil
+	default:
+		return d.ConvertError(numberType)
+	}
+}
+
+// number returns the current Int, Uint or Float value as a Number.
+func (d *Decoder) number() Number {
+	switch d.Type() {
+	case Int:
+		return Number(strconv.FormatInt(d.Int(), 10))
+	case Uint:
+		return Number(strconv.FormatUint(d.Uint(), 10))
+	default:
+		bitSize := 64
+		if d.IsFloat32() {
+			bitSize = 32
+		}
+		s := strconv.FormatFloat(d.Float(), 'g', -1, bitSize)
+		if strings.IndexAny(s, ".eIN") < 0 {
+			// Keep integral values distinct from integers.
+			s += ".0"
+		}
+		return Number(s)
+	}
+}
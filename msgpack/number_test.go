@@ -0,0 +1,26 @@
+package msgpack
+
+import "testing"
+
+func TestNumber(t *testing.T) {
+	for _, n := range []Number{"-1", "18446744073709551615", "1.5", "2.0", "1e+100"} {
+		p, err := Marshal(n)
+		if err != nil {
+			t.Fatalf("encode %s returned error %v", n, err)
+		}
+		var got Number
+		if err := Unmarshal(p, &got); err != nil {
+			t.Fatalf("decode %s returned error %v", n, err)
+		}
+		if got != n {
+			t.Errorf("decode %s returned %s", n, got)
+		}
+	}
+	if _, err := Marshal(Number("x")); err == nil {
+		t.Error("encode invalid number returned nil error")
+	}
+	var n Number
+	if err := Unmarshal([]byte{0xa1, 0x78}, &n); err == nil {
+		t.Error("decode string to Number returned nil error")
+	}
+}
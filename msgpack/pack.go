@@ -4,14 +4,19 @@ import (
 	"errors"
 	"io"
 	"math"
+	"reflect"
+	"time"
 )
 
 // Encoder writes values in MessagePack format.
 type Encoder struct {
-	buf         [32]byte
-	w           io.Writer
-	writeString func(string) (int, error)
-	err         error // permanent error
+	buf          [32]byte
+	w            io.Writer
+	writeString  func(string) (int, error)
+	err          error // permanent error
+	sortMapKeys  bool
+	extensions   map[reflect.Type]*TypeExtension
+	fieldOptions FieldOptions
 }
 
 // NewEncoder allocates and initializes a new Unpacker.
@@ -186,6 +191,38 @@ func (e *Encoder) PackMapLen(n int64) error {
 	return e.packArrayMapLen(fixMapCodeMin, mapLenEncodings, n)
 }
 
+// PackArray writes an array with n elements to the MessagePack stream.
+// PackArray calls elem with the index of each element in order. The elem
+// function must write exactly one value to the stream. PackArray can be used
+// to stream large arrays without holding the elements in memory.
+func (e *Encoder) PackArray(n int, elem func(i int) error) error {
+	if err := e.PackArrayLen(int64(n)); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := elem(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PackMap writes a map with n key-value pairs to the MessagePack stream.
+// PackMap calls entry with the index of each pair in order. The entry
+// function must write exactly two values, the key and the value, to the
+// stream.
+func (e *Encoder) PackMap(n int, entry func(i int) error) error {
+	if err := e.PackMapLen(int64(n)); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := entry(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PackExtension writes an extension to the MessagePack stream.
 func (e *Encoder) PackExtension(kind int, data []byte) error {
 	var b []byte
@@ -282,6 +319,61 @@ func (e *Encoder) PackFloat(f float64) error {
 	return err
 }
 
+// PackTimestamp writes a time value to the MessagePack stream using the
+// timestamp extension type. The value is written in the smallest of the 32,
+// 64 and 96 bit timestamp formats that can represent the value.
+func (e *Encoder) PackTimestamp(t time.Time) error {
+	sec := t.Unix()
+	nsec := uint64(t.Nanosecond())
+	var data []byte
+	switch {
+	case sec>>34 == 0 && nsec == 0 && sec <= math.MaxUint32:
+		data = e.buf[16:20]
+		data[0] = byte(sec >> 24)
+		data[1] = byte(sec >> 16)
+		data[2] = byte(sec >> 8)
+		data[3] = byte(sec)
+	case sec>>34 == 0:
+		n := nsec<<34 | uint64(sec)
+		data = e.buf[16:24]
+		data[0] = byte(n >> 56)
+		data[1] = byte(n >> 48)
+		data[2] = byte(n >> 40)
+		data[3] = byte(n >> 32)
+		data[4] = byte(n >> 24)
+		data[5] = byte(n >> 16)
+		data[6] = byte(n >> 8)
+		data[7] = byte(n)
+	default:
+		data = e.buf[16:28]
+		data[0] = byte(nsec >> 24)
+		data[1] = byte(nsec >> 16)
+		data[2] = byte(nsec >> 8)
+		data[3] = byte(nsec)
+		data[4] = byte(sec >> 56)
+		data[5] = byte(sec >> 48)
+		data[6] = byte(sec >> 40)
+		data[7] = byte(sec >> 32)
+		data[8] = byte(sec >> 24)
+		data[9] = byte(sec >> 16)
+		data[10] = byte(sec >> 8)
+		data[11] = byte(sec)
+	}
+	return e.PackExtension(timestampExtension, data)
+}
+
+// PackFloat32 writes a 32-bit Float value to the MessagePack stream.
+func (e *Encoder) PackFloat32(f float32) error {
+	n := math.Float32bits(f)
+	e.buf[0] = float32Code
+	e.buf[1] = byte(n >> 24)
+	e.buf[2] = byte(n >> 16)
+	e.buf[3] = byte(n >> 8)
+	e.buf[4] = byte(n)
+	_, err := e.w.Write(e.buf[:5])
+	return err
+}
+
 // PackRaw writes bytes directly to the MessagePack stream. It is the
 // application's responsibility to ensure that the bytes are valid.
 func (e *Encoder) PackRaw(p []byte) error {
@@ -1,10 +1,12 @@
 package msgpack
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var packTests = []struct {
@@ -44,6 +46,7 @@ var packTests = []struct {
 	{true, "c3"},
 	{false, "c2"},
 	{float64(1.23456), "cb3ff3c0c1fc8f3238"},
+	{float32(1.5), "ca3fc00000"},
 	{mapLen(0x0), "80"},
 	{mapLen(0x1), "81"},
 	{mapLen(0xf), "8f"},
@@ -71,6 +74,12 @@ var packTests = []struct {
 	{extension{5, "12345678"}, "d7053132333435363738"},
 	{extension{6, "1234567890123456"}, "d80631323334353637383930313233343536"},
 	{extension{7, "12345678901234567"}, "c711073132333435363738393031323334353637"},
+	{time.Unix(1, 0), "d6ff00000001"},
+	{time.Unix(1<<32-1, 0), "d6ffffffffff"},
+	{time.Unix(1, 1), "d7ff0000000400000001"},
+	{time.Unix(1<<34-1, 999999999), "d7ffee6b27ffffffffff"},
+	{time.Unix(1<<34, 0), "c70cff000000000000000400000000"},
+	{time.Unix(-1, 1), "c70cff00000001ffffffffffffffff"},
 }
 
 func TestPack(t *testing.T) {
@@ -97,3 +106,24 @@ tests:
 		}
 	}
 }
+
+func TestPackArrayMap(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	keys := []string{"a", "b"}
+	err := e.PackMap(len(keys), func(i int) error {
+		if err := e.PackString(keys[i]); err != nil {
+			return err
+		}
+		return e.PackArray(i+1, func(j int) error {
+			return e.PackInt(int64(j))
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "82a1619100a162920001"
+	if h := hex.EncodeToString(buf.Bytes()); h != want {
+		t.Errorf("got %s, want %s", h, want)
+	}
+}
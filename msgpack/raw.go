@@ -0,0 +1,23 @@
+package msgpack
+
+// RawMessage is a raw encoded MessagePack value. It implements Marshaler and
+// Unmarshaler and can be used to delay MessagePack decoding or precompute a
+// MessagePack encoding.
+type RawMessage []byte
+
+// MarshalMsgPack writes m to the stream. An empty RawMessage is written as
+// nil.
+func (m RawMessage) MarshalMsgPack(e *Encoder) error {
+	if len(m) == 0 {
+		return e.PackNil()
+	}
+	return e.PackRaw(m)
+}
+
+// UnmarshalMsgPack sets *m to a copy of the encoding of the current value in
+// the stream, including any nested values.
+func (m *RawMessage) UnmarshalMsgPack(d *Decoder) error {
+	p, err := d.appendRaw((*m)[:0])
+	*m = p
+	return err
+}
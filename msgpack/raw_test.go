@@ -0,0 +1,59 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestRawMessage(t *testing.T) {
+	type message struct {
+		Method string
+		Params RawMessage
+		Ptr    *RawMessage
+	}
+
+	// {"Method": "m", "Params": [1, "a", {"k": 1.5}], "Ptr": nil}
+	const h = "83a64d6574686f64a16da6506172616d7393" + "01" + "a161" + "81a16bcb3ff8000000000000" + "a3507472c0"
+	p, err := hex.DecodeString(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m message
+	if err := Unmarshal(p, &m); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(m.Params), "9301a16181a16bcb3ff8000000000000"; got != want {
+		t.Errorf("Params = %s, want %s", got, want)
+	}
+	if m.Ptr != nil {
+		t.Errorf("Ptr = %x, want nil", *m.Ptr)
+	}
+
+	var params []interface{}
+	if err := Unmarshal(m.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if len(params) != 3 || params[1] != "a" {
+		t.Errorf("decoded Params = %#v", params)
+	}
+
+	// Decoding with the bufio based decoder must produce the same result.
+	var m2 message
+	if err := NewDecoder(bytes.NewReader(p)).Decode(&m2); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(m2.Params, m.Params) {
+		t.Errorf("Decode Params = %x, want %x", m2.Params, m.Params)
+	}
+
+	// Encoding writes the raw bytes.
+	out, err := Marshal(&m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(out); got != h {
+		t.Errorf("Marshal returned %s, want %s", got, h)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !plan9
+// +build !plan9
+
+package rpc
+
+import "syscall"
+
+func retryableAcceptErrno(errno syscall.Errno) bool {
+	switch errno {
+	case syscall.ECONNABORTED, syscall.ECONNRESET, syscall.EMFILE, syscall.ENFILE, syscall.ENOBUFS, syscall.ENOMEM:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,8 @@
+package rpc
+
+import "syscall"
+
+// Accept errors on Plan 9 are not reported with an Errno.
+func retryableAcceptErrno(errno syscall.Errno) bool {
+	return false
+}
@@ -3,10 +3,13 @@ package rpc
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"runtime/debug"
+	"sort"
 	"sync"
 
 	"github.com/neovim/go-client/msgpack"
@@ -20,12 +23,14 @@ const (
 
 const (
 	stateInit = iota
+	stateShutdown
 	stateClosed
 )
 
 var (
-	errClosed   = errors.New("msgpack/rpc: session closed")
-	errInternal = errors.New("msgpack/rpc: internal error")
+	errClosed       = errors.New("msgpack/rpc: session closed")
+	errInternal     = errors.New("msgpack/rpc: internal error")
+	errShuttingDown = errors.New("msgpack/rpc: shutting down")
 )
 
 type Error struct {
@@ -42,10 +47,17 @@ type Call struct {
 	Reply  interface{}
 	Err    error
 	Done   chan *Call
+
+	// stop is closed when the call completes. It is used to stop the
+	// goroutine watching the call's context.
+	stop chan struct{}
 }
 
 func (call *Call) done(e *Endpoint, err error) {
 	call.Err = err
+	if call.stop != nil {
+		close(call.stop)
+	}
 	select {
 	case call.Done <- call:
 		// ok
@@ -57,9 +69,13 @@ func (call *Call) done(e *Endpoint, err error) {
 type handler struct {
 	fn   reflect.Value
 	args []reflect.Value
+
+	// ctx is true if the first argument to fn is a context.Context.
+	ctx bool
 }
 
 type notification struct {
+	h      *handler
 	call   func([]reflect.Value) []reflect.Value
 	args   []reflect.Value
 	method string
@@ -74,6 +90,21 @@ type Endpoint struct {
 	closer io.Closer
 	done   chan struct{}
 
+	// ctx is passed to handlers that accept a context. The context is
+	// cancelled when the endpoint is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	clientInterceptors []ClientInterceptor
+	serverInterceptors []ServerInterceptor
+
+	// recoverPanics specifies whether panics in handlers are recovered.
+	recoverPanics bool
+
+	// requests limits the number of running request handlers when not nil.
+	requests       chan struct{}
+	requestsPolicy OverflowPolicy
+
 	packMu sync.Mutex
 	enc    *msgpack.Encoder
 	bw     *bufio.Writer
@@ -84,26 +115,47 @@ type Endpoint struct {
 	state   int
 	err     error
 
-	handlersMu sync.RWMutex
-	handlers   map[string]*handler
-
-	notificationsMu   sync.Mutex
-	notificationsCond *sync.Cond
-	notifications     []*notification
+	// inflight counts the running request handlers and the notifications
+	// waiting to be handled.
+	inflight sync.WaitGroup
+
+	handlersMu    sync.RWMutex
+	handlers      map[string]*handler
+	fallback      FallbackHandler
+	dispatchModes map[string]DispatchMode
+
+	// notifications is the queue for notifications dispatched with
+	// DispatchSerial. methodNotifications holds the queues for notifications
+	// dispatched with DispatchSerialPerMethod. The queues are created and
+	// used by Serve().
+	notifications       *notificationQueue
+	methodNotifications map[string]*notificationQueue
+	maxNotifications    int
+	notificationsPolicy OverflowPolicy
+
+	// concurrentNotifications limits the number of running handlers for
+	// notifications dispatched with DispatchConcurrent when not nil.
+	concurrentNotifications chan struct{}
 }
 
 // NewEndpoint returns a new endpoint with the specified options.
 func NewEndpoint(r io.Reader, w io.Writer, c io.Closer, options ...Option) (*Endpoint, error) {
 	bw := bufio.NewWriter(w)
+	ctx, cancel := context.WithCancel(context.Background())
 	e := &Endpoint{
-		done:     make(chan struct{}),
-		handlers: make(map[string]*handler),
-		pending:  make(map[uint64]*Call),
-		closer:   c,
-		bw:       bw,
-		enc:      msgpack.NewEncoder(bw),
-		dec:      msgpack.NewDecoder(r),
+		logf:          discardLogf,
+		ctx:           ctx,
+		cancel:        cancel,
+		recoverPanics: true,
+		done:          make(chan struct{}),
+		handlers:      make(map[string]*handler),
+		pending:       make(map[uint64]*Call),
+		closer:        c,
+		bw:            bw,
+		enc:           msgpack.NewEncoder(bw),
+		dec:           msgpack.NewDecoder(r),
 	}
+	e.ctx = context.WithValue(e.ctx, endpointKey{}, e)
 	for _, option := range options {
 		option.f(e)
 	}
@@ -119,12 +171,118 @@ func WithExtensions(extensions msgpack.ExtensionMap) Option {
 	}}
 }
 
+// WithDecoderConfig specifies a function for configuring the decoder used by
+// the endpoint. Use this option to enable strict decoding of arguments and
+// replies:
+//
+//	rpc.WithDecoderConfig(func(d *msgpack.Decoder) {
+//		d.DisallowUnknownFields()
+//		d.UseStrictNumbers()
+//	})
+func WithDecoderConfig(f func(d *msgpack.Decoder)) Option {
+	return Option{func(e *Endpoint) {
+		f(e.dec)
+	}}
+}
+
+// WithEncoderConfig specifies a function for configuring the encoder used by
+// the endpoint. Use this option to encode arguments and replies with sorted
+// map keys:
+//
+//	rpc.WithEncoderConfig(func(e *msgpack.Encoder) {
+//		e.SetSortMapKeys(true)
+//	})
+func WithEncoderConfig(f func(e *msgpack.Encoder)) Option {
+	return Option{func(e *Endpoint) {
+		f(e.enc)
+	}}
+}
+
+// WithLogf specifies a function for logging errors that are not reported to
+// the application, such as requests for unknown methods. The errors are
+// discarded if f is nil or the option is not specified.
 func WithLogf(f func(fmt string, args ...interface{})) Option {
 	return Option{func(e *Endpoint) {
+		if f == nil {
+			f = discardLogf
+		}
 		e.logf = f
 	}}
 }
 
+func discardLogf(fmt string, args ...interface{}) {}
+
+// OverflowPolicy specifies what an endpoint does with an incoming message
+// when a limit set by WithMaxConcurrentRequests or WithMaxNotificationQueue is
+// reached.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the incoming message. Dropped requests are
+	// answered with an error. OverflowDropNewest is the default policy.
+	OverflowDropNewest OverflowPolicy = iota
+
+	// OverflowDropOldest drops the oldest queued notification to make room
+	// for the incoming notification. Requests are not queued, so this policy
+	// is the same as OverflowDropNewest for requests.
+	OverflowDropOldest
+
+	// OverflowBlock stops reading messages from the peer until the
+	// message can be handled. While the endpoint is blocked, it also does not
+	// read the replies to its own calls. A handler that calls the peer when
+	// the limit is reached deadlocks the endpoint. Use OverflowBlock only if
+	// handlers never call the peer.
+	OverflowBlock
+)
+
+// WithMaxConcurrentRequests limits the number of request handlers running
+// concurrently to n. The policy specifies what happens to requests received
+// when the limit is reached.
+func WithMaxConcurrentRequests(n int, policy OverflowPolicy) Option {
+	return Option{func(e *Endpoint) {
+		if n > 0 {
+			e.requests = make(chan struct{}, n)
+		} else {
+			e.requests = nil
+		}
+		e.requestsPolicy = policy
+	}}
+}
+
+// WithMaxNotificationQueue limits the number of notifications waiting to be
+// handled to n. The policy specifies what happens to notifications received
+// when the queue is full.
+//
+// The limit applies to each queue separately: the queue shared by methods
+// dispatched with DispatchSerial and the queue of each method dispatched with
+// DispatchSerialPerMethod. The limit also applies to the number of running
+// handlers for methods dispatched with DispatchConcurrent. Running handlers
+// cannot be dropped, so OverflowDropOldest is the same as OverflowDropNewest
+// for these methods.
+//
+// With OverflowBlock, a notification handler that calls the peer while the
+// queue is full deadlocks the endpoint as described for OverflowBlock.
+func WithMaxNotificationQueue(n int, policy OverflowPolicy) Option {
+	return Option{func(e *Endpoint) {
+		e.maxNotifications = n
+		e.notificationsPolicy = policy
+		if n > 0 {
+			e.concurrentNotifications = make(chan struct{}, n)
+		} else {
+			e.concurrentNotifications = nil
+		}
+	}}
+}
+
+// WithRecoverPanics specifies whether the endpoint recovers panics in
+// handlers. A recovered panic is logged with a stack trace and, for requests,
+// reported to the peer as an error. Panics are recovered by default.
+func WithRecoverPanics(recover bool) Option {
+	return Option{func(e *Endpoint) {
+		e.recoverPanics = recover
+	}}
+}
+
 func (e *Endpoint) decodeUint(what string) (uint64, error) {
 	if err := e.dec.Unpack(); err != nil {
 		return 0, err
@@ -158,49 +316,133 @@ func (e *Endpoint) skip(n int) error {
 	return nil
 }
 
-var errorType = reflect.ValueOf(new(error)).Elem().Type()
+var (
+	errorType   = reflect.ValueOf(new(error)).Elem().Type()
+	contextType = reflect.ValueOf(new(context.Context)).Elem().Type()
+)
+
+type endpointKey struct{}
+
+// EndpointFromContext returns the endpoint stored in the context passed to
+// handlers.
+func EndpointFromContext(ctx context.Context) (*Endpoint, bool) {
+	e, ok := ctx.Value(endpointKey{}).(*Endpoint)
+	return e, ok
+}
 
 // Register registers handler fn for the specified method name.
 //
 // When servicing a call, the arguments to fn are the values in args followed
-// by the values passed from the peer.
+// by the values passed from the peer. If the first argument to fn is a
+// context.Context, then the endpoint passes a context that is cancelled when
+// the endpoint is closed, followed by the values in args and the values
+// passed from the peer. Use EndpointFromContext to get the endpoint from the
+// context.
+//
+// Arguments of type msgpack.RawMessage receive the undecoded MessagePack
+// encoding of the value passed from the peer.
 func (e *Endpoint) Register(method string, fn interface{}, args ...interface{}) error {
+	h, err := newHandler(fn, args)
+	if err != nil {
+		return err
+	}
+	e.handlersMu.Lock()
+	e.handlers[method] = h
+	e.handlersMu.Unlock()
+	return nil
+}
+
+func newHandler(fn interface{}, args []interface{}) (*handler, error) {
 	v := reflect.ValueOf(fn)
 	t := v.Type()
 	if t.Kind() != reflect.Func {
-		return errors.New("msgpack/rpc: handler not a function")
-	}
-	if t.NumIn() < len(args) {
-		return fmt.Errorf("msgpack/rpc: handler must have at least %d args", len(args))
+		return nil, errors.New("msgpack/rpc: handler not a function")
 	}
 
 	h := &handler{fn: v, args: make([]reflect.Value, len(args))}
 
+	offset := 0
+	if t.NumIn() > 0 && t.In(0) == contextType {
+		h.ctx = true
+		offset = 1
+	}
+
+	if t.NumIn() < len(args)+offset {
+		return nil, fmt.Errorf("msgpack/rpc: handler must have at least %d args", len(args)+offset)
+	}
+
 	for i, arg := range args {
 		if arg == nil {
-			t := t.In(i)
+			t := t.In(i + offset)
 			switch t.Kind() {
 			case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
 				h.args[i] = reflect.New(t).Elem()
 			default:
-				return fmt.Errorf("msgpack/rpc: handler arg %d must be interface, pointer, map or slice", i)
+				return nil, fmt.Errorf("msgpack/rpc: handler arg %d must be interface, pointer, map or slice", i)
 			}
 		} else {
 			h.args[i] = reflect.ValueOf(arg)
-			if t.In(i) != h.args[i].Type() {
-				return fmt.Errorf("msgpack/rpc: handler arg %d must be type %T", i, arg)
+			if t.In(i+offset) != h.args[i].Type() {
+				return nil, fmt.Errorf("msgpack/rpc: handler arg %d must be type %T", i, arg)
 			}
 		}
 	}
 
 	if t.NumOut() > 2 || (t.NumOut() > 0 && t.Out(t.NumOut()-1) != errorType) {
-		return errors.New("msgpack/rpc: handler return must be (), (error) or (valueType, error)")
+		return nil, errors.New("msgpack/rpc: handler return must be (), (error) or (valueType, error)")
 	}
 
+	return h, nil
+}
+
+// Unregister removes the handler for the specified method name.
+func (e *Endpoint) Unregister(method string) {
 	e.handlersMu.Lock()
-	e.handlers[method] = h
+	delete(e.handlers, method)
 	e.handlersMu.Unlock()
-	return nil
+}
+
+// Methods returns the sorted names of the methods with registered handlers.
+func (e *Endpoint) Methods() []string {
+	e.handlersMu.RLock()
+	methods := make([]string, 0, len(e.handlers))
+	for method := range e.handlers {
+		methods = append(methods, method)
+	}
+	e.handlersMu.RUnlock()
+	sort.Strings(methods)
+	return methods
+}
+
+// FallbackHandler handles requests and notifications for methods without a
+// registered handler. The args are the undecoded MessagePack encodings of the
+// values passed from the peer. Use msgpack.Unmarshal to decode the args. The
+// ctx is cancelled when the endpoint is closed.
+type FallbackHandler func(ctx context.Context, method string, args []msgpack.RawMessage) (interface{}, error)
+
+// SetFallbackHandler sets the handler for methods without a registered
+// handler. If f is nil, requests for unknown methods are answered with an
+// error and notifications for unknown methods are dropped.
+func (e *Endpoint) SetFallbackHandler(f FallbackHandler) {
+	e.handlersMu.Lock()
+	e.fallback = f
+	e.handlersMu.Unlock()
+}
+
+// lookupHandler returns the handler for method or a handler calling the
+// fallback handler if there is no handler registered for method.
+func (e *Endpoint) lookupHandler(method string) (*handler, bool) {
+	e.handlersMu.RLock()
+	h, ok := e.handlers[method]
+	fallback := e.fallback
+	e.handlersMu.RUnlock()
+	if ok || fallback == nil {
+		return h, ok
+	}
+	fn := func(ctx context.Context, args ...msgpack.RawMessage) (interface{}, error) {
+		return fallback(ctx, method, args)
+	}
+	return &handler{fn: reflect.ValueOf(fn), ctx: true}, true
 }
 
 func (e *Endpoint) close(err error) error {
@@ -215,6 +457,7 @@ func (e *Endpoint) close(err error) error {
 		call.done(e, errClosed)
 	}
 	e.pending = nil
+	e.cancel()
 	err = e.closer.Close()
 	if e.err == nil {
 		e.err = err
@@ -227,12 +470,82 @@ func (e *Endpoint) Close() error {
 	return e.close(nil)
 }
 
+// Shutdown gracefully shuts down the endpoint. Shutdown stops handling new
+// requests and notifications from the peer, waits for running request handlers
+// and queued notifications to complete, and then closes the endpoint. Requests
+// received during shutdown are answered with an error. Calls to the peer are
+// allowed until the endpoint is closed.
+//
+// If ctx is done before the handlers complete, Shutdown closes the endpoint
+// and returns ctx.Err().
+//
+// Shutdown waits for all handlers to complete. Do not call Shutdown from a
+// handler.
+func (e *Endpoint) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if e.state == stateInit {
+		e.state = stateShutdown
+	}
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return e.Close()
+	case <-ctx.Done():
+		e.Close()
+		return ctx.Err()
+	}
+}
+
+// startHandler increments the count of inflight handlers. It returns false if
+// the endpoint is shutting down or closed.
+func (e *Endpoint) startHandler() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.state != stateInit {
+		return false
+	}
+	e.inflight.Add(1)
+	return true
+}
+
+// Call invokes the named method on the peer and waits for it to complete.
 func (e *Endpoint) Call(method string, reply interface{}, args ...interface{}) error {
-	c := <-e.Go(method, make(chan *Call, 1), reply, args...).Done
-	return c.Err
+	return e.CallContext(context.Background(), method, reply, args...)
+}
+
+// CallContext is like Call, but returns ctx.Err() if the context is done
+// before the peer replies.
+func (e *Endpoint) CallContext(ctx context.Context, method string, reply interface{}, args ...interface{}) error {
+	invoke := func() error {
+		c := <-e.GoContext(ctx, method, make(chan *Call, 1), reply, args...).Done
+		return c.Err
+	}
+	if len(e.clientInterceptors) == 0 {
+		return invoke()
+	}
+	return e.interceptCall(ctx, method, reply, args, invoke)
 }
 
+// Go invokes the named method asynchronously. It returns the Call structure
+// representing the invocation. The done channel will signal when the call is
+// complete by returning the same Call object. If done is nil, Go will allocate
+// a new channel. If non-nil, done must be buffered or Go will deliberately
+// crash.
 func (e *Endpoint) Go(method string, done chan *Call, reply interface{}, args ...interface{}) *Call {
+	return e.GoContext(context.Background(), method, done, reply, args...)
+}
+
+// GoContext is like Go, but completes the call with ctx.Err() if the context
+// is done before the peer replies. A reply received after the context is done
+// is discarded.
+func (e *Endpoint) GoContext(ctx context.Context, method string, done chan *Call, reply interface{}, args ...interface{}) *Call {
 	if args == nil {
 		args = []interface{}{}
 	}
@@ -250,6 +563,15 @@ func (e *Endpoint) Go(method string, done chan *Call, reply interface{}, args ..
 		Done:   done,
 	}
 
+	if err := ctx.Err(); err != nil {
+		call.done(e, err)
+		return call
+	}
+
+	if ctx.Done() != nil {
+		call.stop = make(chan struct{})
+	}
+
 	e.mu.Lock()
 	if e.state == stateClosed {
 		call.done(e, errClosed)
@@ -261,6 +583,10 @@ func (e *Endpoint) Go(method string, done chan *Call, reply interface{}, args ..
 	e.pending[id] = call
 	e.mu.Unlock()
 
+	if call.stop != nil {
+		go e.cancelOnDone(ctx, id, call)
+	}
+
 	message := &struct {
 		Kind   int `msgpack:",array"`
 		ID     uint64
@@ -293,10 +619,34 @@ func (e *Endpoint) Go(method string, done chan *Call, reply interface{}, args ..
 	return call
 }
 
+// cancelOnDone completes the pending call with id when ctx is done.
+func (e *Endpoint) cancelOnDone(ctx context.Context, id uint64, call *Call) {
+	select {
+	case <-ctx.Done():
+		e.mu.Lock()
+		if e.pending[id] == call {
+			delete(e.pending, id)
+			call.done(e, ctx.Err())
+		}
+		e.mu.Unlock()
+	case <-call.stop:
+	}
+}
+
+// Notify sends a notification for the named method to the peer.
 func (e *Endpoint) Notify(method string, args ...interface{}) error {
 	if args == nil {
 		args = []interface{}{}
 	}
+	if len(e.clientInterceptors) == 0 {
+		return e.notify(method, args)
+	}
+	return e.interceptCall(context.Background(), method, nil, args, func() error {
+		return e.notify(method, args)
+	})
+}
+
+func (e *Endpoint) notify(method string, args []interface{}) error {
 
 	message := &struct {
 		Kind   int `msgpack:",array"`
@@ -362,9 +712,8 @@ func (e *Endpoint) reply(id uint64, replyErr error, reply interface{}) error {
 // Serve serves incoming requests. Serve blocks until the peer disconnects or
 // there is an error.
 func (e *Endpoint) Serve() error {
-	e.notificationsCond = sync.NewCond(&e.notificationsMu)
-	defer e.enqueNotification(nil)
-	go e.runNotifications()
+	e.notifications = e.newNotificationQueue()
+	defer e.stopNotifications()
 
 	for {
 		if err := e.dec.Unpack(); err != nil {
@@ -437,8 +786,8 @@ func (e *Endpoint) handleReply(messageLen int) error {
 		err = e.skip(1)
 	} else {
 		err = e.dec.Decode(call.Reply)
-		if cvterr, ok := err.(*msgpack.DecodeConvertError); ok {
-			call.done(e, cvterr)
+		if isDecodeError(err) {
+			call.done(e, err)
 			return nil
 		}
 	}
@@ -452,11 +801,27 @@ func (e *Endpoint) handleReply(messageLen int) error {
 	return nil
 }
 
+// isDecodeError returns whether err is an error from decoding a value that
+// does not leave the decoder in an invalid state.
+func isDecodeError(err error) bool {
+	switch err.(type) {
+	case *msgpack.DecodeConvertError, *msgpack.UnknownFieldError:
+		return true
+	default:
+		return false
+	}
+}
+
 func (e *Endpoint) createCall(h *handler) (func([]reflect.Value) []reflect.Value, []reflect.Value, error) {
 	t := h.fn.Type()
 	args := make([]reflect.Value, t.NumIn())
+	offset := 0
+	if h.ctx {
+		args[0] = reflect.ValueOf(e.ctx)
+		offset = 1
+	}
 	for i := range h.args {
-		args[i] = h.args[i]
+		args[offset+i] = h.args[i]
 	}
 	if err := e.dec.Unpack(); err != nil {
 		return nil, nil, err
@@ -473,7 +838,7 @@ func (e *Endpoint) createCall(h *handler) (func([]reflect.Value) []reflect.Value
 	srcIndex := 0
 	srcLen := e.dec.Len()
 
-	dstIndex := len(h.args)
+	dstIndex := offset + len(h.args)
 	dstLen := t.NumIn()
 	if t.IsVariadic() {
 		dstLen--
@@ -486,7 +851,7 @@ func (e *Endpoint) createCall(h *handler) (func([]reflect.Value) []reflect.Value
 		if srcIndex < srcLen {
 			srcIndex++
 			err := e.dec.Decode(v.Interface())
-			if _, ok := err.(*msgpack.DecodeConvertError); ok {
+			if isDecodeError(err) {
 				if savedErr == nil {
 					savedErr = err
 				}
@@ -521,7 +886,7 @@ func (e *Endpoint) createCall(h *handler) (func([]reflect.Value) []reflect.Value
 
 	for i := 0; i < n; i++ {
 		err := e.dec.Decode(v.Index(i).Addr().Interface())
-		if _, ok := err.(*msgpack.DecodeConvertError); ok {
+		if isDecodeError(err) {
 			if savedErr == nil {
 				savedErr = err
 			}
@@ -533,6 +898,62 @@ func (e *Endpoint) createCall(h *handler) (func([]reflect.Value) []reflect.Value
 	return h.fn.CallSlice, args, nil
 }
 
+// invoke calls handler h for method with the arguments returned from
+// createCall.
+func (e *Endpoint) invoke(method string, h *handler, call func([]reflect.Value) []reflect.Value, args []reflect.Value) (reply interface{}, err error) {
+	if e.recoverPanics {
+		defer e.recoverHandler(method, &err)
+	}
+	handle := func() (reply interface{}, err error) {
+		if e.recoverPanics {
+			defer e.recoverHandler(method, &err)
+		}
+		out := call(args)
+		var replyErr error
+		var replyVal interface{}
+		switch len(out) {
+		case 1:
+			replyErr, _ = out[0].Interface().(error)
+		case 2:
+			replyVal = out[0].Interface()
+			replyErr, _ = out[1].Interface().(error)
+		}
+		return replyVal, replyErr
+	}
+	if len(e.serverInterceptors) == 0 {
+		return handle()
+	}
+	return e.interceptHandler(method, h.peerArgs(args), handle)
+}
+
+// recoverHandler recovers a panic in the handler for method and sets *err to
+// an error describing the panic.
+func (e *Endpoint) recoverHandler(method string, err *error) {
+	if r := recover(); r != nil {
+		e.logf("msgpack/rpc: panic in service method %s: %v\n%s", method, r, debug.Stack())
+		*err = fmt.Errorf("msgpack/rpc: panic in service method %s: %v", method, r)
+	}
+}
+
+// peerArgs returns the arguments passed from the peer in args.
+func (h *handler) peerArgs(args []reflect.Value) []interface{} {
+	i := len(h.args)
+	if h.ctx {
+		i++
+	}
+	var result []interface{}
+	for ; i < len(args); i++ {
+		if i == len(args)-1 && h.fn.Type().IsVariadic() {
+			for j := 0; j < args[i].Len(); j++ {
+				result = append(result, args[i].Index(j).Interface())
+			}
+			break
+		}
+		result = append(result, args[i].Interface())
+	}
+	return result
+}
+
 func (e *Endpoint) handleRequest(messageLen int) error {
 	if messageLen != 4 {
 		// messageType, id, method, args
@@ -549,9 +970,7 @@ func (e *Endpoint) handleRequest(messageLen int) error {
 		return err
 	}
 
-	e.handlersMu.RLock()
-	h, ok := e.handlers[method]
-	e.handlersMu.RUnlock()
+	h, ok := e.lookupHandler(method)
 
 	if !ok {
 		if err := e.skip(1); err != nil {
@@ -562,23 +981,41 @@ func (e *Endpoint) handleRequest(messageLen int) error {
 	}
 
 	call, args, err := e.createCall(h)
-	if _, ok := err.(*msgpack.DecodeConvertError); ok {
+	if isDecodeError(err) {
 		e.logf("msgpack/rpc: %s: %v", method, err)
 		return e.reply(id, errors.New("invalid argument"), nil)
 	} else if err != nil {
 		return err
 	}
 
+	if e.requests != nil {
+		select {
+		case e.requests <- struct{}{}:
+		default:
+			if e.requestsPolicy != OverflowBlock {
+				e.logf("msgpack/rpc: too many concurrent requests, dropping request %s", method)
+				return e.reply(id, errors.New("msgpack/rpc: too many concurrent requests"), nil)
+			}
+			select {
+			case e.requests <- struct{}{}:
+			case <-e.ctx.Done():
+				return errClosed
+			}
+		}
+	}
+
+	if !e.startHandler() {
+		if e.requests != nil {
+			<-e.requests
+		}
+		return e.reply(id, errShuttingDown, nil)
+	}
+
 	go func() {
-		out := call(args)
-		var replyErr error
-		var replyVal interface{}
-		switch h.fn.Type().NumOut() {
-		case 1:
-			replyErr, _ = out[0].Interface().(error)
-		case 2:
-			replyVal = out[0].Interface()
-			replyErr, _ = out[1].Interface().(error)
+		defer e.inflight.Done()
+		replyVal, replyErr := e.invoke(method, h, call, args)
+		if e.requests != nil {
+			<-e.requests
 		}
 		if err := e.reply(id, replyErr, replyVal); err != nil {
 			e.close(err)
@@ -599,9 +1036,7 @@ func (e *Endpoint) handleNotification(messageLen int) error {
 		return err
 	}
 
-	e.handlersMu.RLock()
-	h, ok := e.handlers[method]
-	e.handlersMu.RUnlock()
+	h, ok := e.lookupHandler(method)
 
 	if !ok {
 		e.logf("msgpack/rpc: notification service method %s not found", method)
@@ -609,49 +1044,172 @@ func (e *Endpoint) handleNotification(messageLen int) error {
 	}
 
 	call, args, err := e.createCall(h)
-	if err != nil {
+	if isDecodeError(err) {
+		e.logf("msgpack/rpc: %s: %v", method, err)
+		return nil
+	} else if err != nil {
 		return err
 	}
 
-	e.enqueNotification(&notification{call: call, args: args, method: method})
+	if !e.startHandler() {
+		e.logf("msgpack/rpc: shutting down, dropping notification %s", method)
+		return nil
+	}
+
+	n := &notification{h: h, call: call, args: args, method: method}
+
+	e.handlersMu.RLock()
+	mode := e.dispatchModes[method]
+	e.handlersMu.RUnlock()
+
+	switch mode {
+	case DispatchConcurrent:
+		if e.concurrentNotifications != nil {
+			select {
+			case e.concurrentNotifications <- struct{}{}:
+			default:
+				if e.notificationsPolicy != OverflowBlock {
+					e.logf("msgpack/rpc: too many concurrent notifications, dropping notification %s", method)
+					e.inflight.Done()
+					return nil
+				}
+				select {
+				case e.concurrentNotifications <- struct{}{}:
+				case <-e.ctx.Done():
+					e.inflight.Done()
+					return errClosed
+				}
+			}
+		}
+		go func() {
+			e.runNotification(n)
+			if e.concurrentNotifications != nil {
+				<-e.concurrentNotifications
+			}
+		}()
+	case DispatchSerialPerMethod:
+		q := e.methodNotifications[method]
+		if q == nil {
+			if e.methodNotifications == nil {
+				e.methodNotifications = make(map[string]*notificationQueue)
+			}
+			q = e.newNotificationQueue()
+			e.methodNotifications[method] = q
+		}
+		q.enqueue(n)
+	default:
+		e.notifications.enqueue(n)
+	}
 	return nil
 }
 
-func (e *Endpoint) enqueNotification(n *notification) {
-	e.notificationsMu.Lock()
-	e.notifications = append(e.notifications, n)
-	e.notificationsCond.Signal()
-	e.notificationsMu.Unlock()
+// stopNotifications stops the goroutines running the notification queues.
+func (e *Endpoint) stopNotifications() {
+	e.notifications.enqueue(nil)
+	for _, q := range e.methodNotifications {
+		q.enqueue(nil)
+	}
+}
+
+func (e *Endpoint) runNotification(n *notification) {
+	defer e.inflight.Done()
+	if _, err := e.invoke(n.method, n.h, n.call, n.args); err != nil {
+		e.logf("msgpack/rpc: service method %s returned %v", n.method, err)
+	}
 }
 
-func (e *Endpoint) dequeueNotifications() []*notification {
-	e.notificationsMu.Lock()
-	for e.notifications == nil {
-		e.notificationsCond.Wait()
+// DispatchMode specifies how notifications for a method are dispatched to
+// the method's handler.
+type DispatchMode int
+
+const (
+	// DispatchSerial runs the handler in a goroutine shared by all methods
+	// with this mode. The notifications are handled in the order received.
+	// This is the default.
+	DispatchSerial DispatchMode = iota
+
+	// DispatchSerialPerMethod runs the handler in a goroutine dedicated to
+	// the method. The notifications for the method are handled in the order
+	// received, without waiting for the handlers of other methods.
+	DispatchSerialPerMethod
+
+	// DispatchConcurrent runs the handler for each notification in a new
+	// goroutine. The notifications are not ordered.
+	DispatchConcurrent
+)
+
+// SetDispatchMode sets the dispatch mode for notifications of the specified
+// method.
+func (e *Endpoint) SetDispatchMode(method string, mode DispatchMode) {
+	e.handlersMu.Lock()
+	if e.dispatchModes == nil {
+		e.dispatchModes = make(map[string]DispatchMode)
 	}
-	notifications := e.notifications
-	e.notifications = nil
-	e.notificationsMu.Unlock()
+	e.dispatchModes[method] = mode
+	e.handlersMu.Unlock()
+}
+
+// notificationQueue runs notifications in a single goroutine to ensure that
+// the notifications are processed in order by the application.
+type notificationQueue struct {
+	e             *Endpoint
+	mu            sync.Mutex
+	cond          *sync.Cond
+	notifications []*notification
+}
+
+func (e *Endpoint) newNotificationQueue() *notificationQueue {
+	q := &notificationQueue{e: e}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+func (q *notificationQueue) enqueue(n *notification) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e := q.e
+	// The nil notification enqueued by Serve() is never dropped.
+	for n != nil && e.maxNotifications > 0 && len(q.notifications) >= e.maxNotifications {
+		switch e.notificationsPolicy {
+		case OverflowDropNewest:
+			e.logf("msgpack/rpc: notification queue full, dropping notification %s", n.method)
+			e.inflight.Done()
+			return
+		case OverflowDropOldest:
+			e.logf("msgpack/rpc: notification queue full, dropping notification %s", q.notifications[0].method)
+			q.notifications = q.notifications[1:]
+			e.inflight.Done()
+		default:
+			q.cond.Wait()
+		}
+	}
+	q.notifications = append(q.notifications, n)
+	q.cond.Broadcast()
+}
+
+func (q *notificationQueue) dequeue() []*notification {
+	q.mu.Lock()
+	for q.notifications == nil {
+		q.cond.Wait()
+	}
+	notifications := q.notifications
+	q.notifications = nil
+	// Wake up Serve() if blocked on a full queue.
+	q.cond.Broadcast()
+	q.mu.Unlock()
 	return notifications
 }
 
-// runNotifications runs notifications in a single goroutine to ensure that the
-// notifications are processed in order by the application.
-func (e *Endpoint) runNotifications() {
+func (q *notificationQueue) run() {
 	for {
-		notifications := e.dequeueNotifications()
+		notifications := q.dequeue()
 		for _, n := range notifications {
 			if n == nil {
 				// Serve() enqueues nil on return
 				return
 			}
-			out := n.call(n.args)
-			if len(out) > 0 {
-				replyErr, _ := out[len(out)-1].Interface().(error)
-				if replyErr != nil {
-					e.logf("msgpack/rpc: service method %s returned %v", n.method, replyErr)
-				}
-			}
+			q.e.runNotification(n)
 		}
 	}
 }
@@ -1,18 +1,25 @@
 package rpc
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/neovim/go-client/msgpack"
 )
 
 func clientServer(t *testing.T, options ...Option) (*Endpoint, *Endpoint, func()) {
 	var wg sync.WaitGroup
 
-	options = append(options, WithLogf(t.Logf))
+	options = append([]Option{WithLogf(t.Logf)}, options...)
 
 	serverConn, clientConn := net.Pipe()
 
@@ -122,7 +129,7 @@ func TestArgs(t *testing.T) {
 	defer cleanup()
 
 	if err := server.Register("n", func(a, b string) ([]string, error) {
-		return append([]string{a, b}), nil
+		return []string{a, b}, nil
 	}); err != nil {
 		t.Fatal(err)
 	}
@@ -166,6 +173,705 @@ func TestCallAfterClose(t *testing.T) {
 	}
 }
 
+func TestCallContext(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	release := make(chan struct{})
+	if err := server.Register("block", func() (string, error) {
+		<-release
+		return "done", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var result string
+	if err := client.CallContext(ctx, "block", &result); err != context.DeadlineExceeded {
+		t.Fatalf("CallContext returned %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := client.CallContext(ctx, "block", &result); err != context.Canceled {
+		t.Fatalf("CallContext returned %v, want %v", err, context.Canceled)
+	}
+
+	client.mu.Lock()
+	n := len(client.pending)
+	client.mu.Unlock()
+	if n != 0 {
+		t.Errorf("%d pending calls after cancellation, want 0", n)
+	}
+}
+
+func TestHandlerContext(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	if err := server.Register("wait", func(ctx context.Context, s string) error {
+		if s != "hello" {
+			t.Errorf("s = %q, want %q", s, "hello")
+		}
+		close(started)
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Notify("wait", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	cleanup()
+
+	select {
+	case err := <-cancelled:
+		if err != context.Canceled {
+			t.Errorf("handler context error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler context not cancelled on close")
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		clientLog []string
+		serverLog []string
+	)
+
+	client, server, cleanup := clientServer(t,
+		WithClientInterceptor(func(ctx context.Context, method string, reply interface{}, args []interface{}, invoke func() error) error {
+			err := invoke()
+			mu.Lock()
+			clientLog = append(clientLog, fmt.Sprintf("%s%v %v", method, args, err))
+			mu.Unlock()
+			return err
+		}),
+		WithServerInterceptor(func(ctx context.Context, method string, args []interface{}, handle func() (interface{}, error)) (interface{}, error) {
+			if method == "denied" {
+				return nil, errors.New("permission denied")
+			}
+			reply, err := handle()
+			mu.Lock()
+			serverLog = append(serverLog, fmt.Sprintf("%s%v %v %v", method, args, reply, err))
+			mu.Unlock()
+			return reply, err
+		}))
+	defer cleanup()
+
+	if err := server.Register("add", func(a, b int) (int, error) { return a + b, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("join", func(sep string, x ...string) (string, error) {
+		return strings.Join(x, sep), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("denied", func() error {
+		t.Error("denied handler called")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var sum int
+	if err := client.Call("add", &sum, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	if err := client.Call("join", &s, "-", "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call("denied", nil); err == nil || err.Error() != "permission denied" {
+		t.Errorf("denied returned %v, want permission denied", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	wantClient := []string{
+		"add[1 2] <nil>",
+		"join[- a b] <nil>",
+		"denied[] permission denied",
+	}
+	if !reflect.DeepEqual(clientLog, wantClient) {
+		t.Errorf("client log = %q, want %q", clientLog, wantClient)
+	}
+
+	wantServer := []string{
+		"add[1 2] 3 <nil>",
+		"join[- a b] a-b <nil>",
+	}
+	if !reflect.DeepEqual(serverLog, wantServer) {
+		t.Errorf("server log = %q, want %q", serverLog, wantServer)
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	logged := make(chan string, 2)
+	client, server, cleanup := clientServer(t, WithLogf(func(format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		t.Log(msg)
+		if strings.Contains(msg, "panic") {
+			logged <- msg
+		}
+	}))
+	defer cleanup()
+
+	if err := server.Register("panic", func() error { panic("ouch") }); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("add", func(a, b int) (int, error) { return a + b, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	err := client.Call("panic", nil)
+	if err == nil || !strings.Contains(err.Error(), "ouch") {
+		t.Errorf("panic returned %v, want error containing %q", err, "ouch")
+	}
+	if msg := <-logged; !strings.Contains(msg, "goroutine") {
+		t.Errorf("logged %q, want stack trace", msg)
+	}
+
+	if err := client.Notify("panic"); err != nil {
+		t.Fatal(err)
+	}
+	<-logged
+
+	var sum int
+	if err := client.Call("add", &sum, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 3 {
+		t.Errorf("sum = %d, want %d", sum, 3)
+	}
+}
+
+func TestRecoverPanicsWithoutLogger(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	server, err := NewEndpoint(serverConn, serverConn, serverConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewEndpoint(clientConn, clientConn, clientConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	go client.Serve()
+	defer server.Close()
+	defer client.Close()
+
+	if err := server.Register("panic", func() error { panic("ouch") }); err != nil {
+		t.Fatal(err)
+	}
+	err = client.Call("panic", nil)
+	if err == nil || !strings.Contains(err.Error(), "ouch") {
+		t.Errorf("panic returned %v, want error containing %q", err, "ouch")
+	}
+	if err := client.Call("panic", nil); err == nil {
+		t.Error("second call returned nil error")
+	}
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	client, server, cleanup := clientServer(t, WithMaxConcurrentRequests(1, OverflowDropNewest))
+	defer cleanup()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if err := server.Register("block", func() error {
+		close(started)
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("ping", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	call := client.Go("block", nil, nil)
+	<-started
+
+	if err := client.Call("ping", nil); err == nil {
+		t.Error("ping succeeded with a blocked request in flight, want error")
+	}
+
+	close(release)
+	if c := <-call.Done; c.Err != nil {
+		t.Fatal(c.Err)
+	}
+	if err := client.Call("ping", nil); err != nil {
+		t.Errorf("ping returned %v after blocked request completed", err)
+	}
+}
+
+func TestMaxConcurrentRequestsCallback(t *testing.T) {
+	// The default policy must keep reading replies to the server's calls
+	// while the limit is reached.
+	client, server, cleanup := clientServer(t, WithMaxConcurrentRequests(1, OverflowPolicy(0)))
+	defer cleanup()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if err := client.Register("inner", func() (string, error) {
+		close(started)
+		<-release
+		return "pong", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("outer", func() (string, error) {
+		var s string
+		err := server.Call("inner", &s)
+		return s, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var reply string
+	call := client.Go("outer", nil, &reply)
+	<-started
+
+	// The server reads this request before the reply to its call.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.CallContext(ctx, "outer", nil); err == nil {
+		t.Error("second outer call succeeded with the limit reached, want error")
+	}
+
+	close(release)
+	select {
+	case c := <-call.Done:
+		if c.Err != nil {
+			t.Fatal(c.Err)
+		}
+		if reply != "pong" {
+			t.Errorf("outer returned %q, want %q", reply, "pong")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("outer call did not complete")
+	}
+}
+
+func TestMaxNotificationQueue(t *testing.T) {
+	client, server, cleanup := clientServer(t, WithMaxNotificationQueue(1, OverflowDropNewest))
+	defer cleanup()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	received := make(chan string, 10)
+	if err := server.Register("n", func(s string) {
+		if s == "first" {
+			close(started)
+			<-release
+		}
+		received <- s
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("sync", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Notify("n", "first"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	for _, s := range []string{"queued", "dropped"} {
+		if err := client.Notify("n", s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The server reads messages in order. Wait for the notifications above
+	// to be queued or dropped.
+	if err := client.Call("sync", nil); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+
+	for _, want := range []string{"first", "queued", "last"} {
+		if want == "last" {
+			if err := client.Notify("n", "last"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := <-received; got != want {
+			t.Fatalf("got notification %q, want %q", got, want)
+		}
+	}
+}
+
+func TestMaxConcurrentNotifications(t *testing.T) {
+	client, server, cleanup := clientServer(t, WithMaxNotificationQueue(1, OverflowDropNewest))
+	defer cleanup()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	received := make(chan string, 10)
+	if err := server.Register("n", func(s string) {
+		if s == "first" {
+			close(started)
+			<-release
+		}
+		received <- s
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("sync", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	server.SetDispatchMode("n", DispatchConcurrent)
+
+	if err := client.Notify("n", "first"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := client.Notify("n", "dropped"); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the notification above to be dropped.
+	if err := client.Call("sync", nil); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if got := <-received; got != "first" {
+		t.Fatalf("got notification %q, want %q", got, "first")
+	}
+
+	// The handler slot is released after the handler returns. Retry until
+	// the notification is not dropped.
+	for {
+		if err := client.Notify("n", "last"); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Call("sync", nil); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-received:
+			if got != "last" {
+				t.Fatalf("got notification %q, want %q", got, "last")
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+
+func TestMaxNotificationQueueCallback(t *testing.T) {
+	for _, mode := range []DispatchMode{DispatchSerial, DispatchConcurrent} {
+		// The default policy must keep reading replies to the server's
+		// calls while the limit is reached.
+		client, server, cleanup := clientServer(t, WithMaxNotificationQueue(1, OverflowPolicy(0)))
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		if err := client.Register("inner", func() (string, error) {
+			close(started)
+			<-release
+			return "pong", nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		done := make(chan error, 1)
+		if err := server.Register("n", func(s string) {
+			if s == "first" {
+				done <- server.Call("inner", nil)
+			}
+		}); err != nil {
+			t.Fatal(err)
+		}
+		server.SetDispatchMode("n", mode)
+
+		if err := client.Notify("n", "first"); err != nil {
+			t.Fatal(err)
+		}
+		<-started
+		// The server reads these notifications before the reply to its
+		// call.
+		for _, s := range []string{"second", "third"} {
+			if err := client.Notify("n", s); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		close(release)
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("mode %d: call from handler returned %v", mode, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("mode %d: call from handler did not complete", mode)
+		}
+		cleanup()
+	}
+}
+func TestDispatchMode(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	release := make(chan struct{})
+	received := make(chan string, 10)
+	if err := server.Register("slow", func(s string) {
+		<-release
+		received <- s
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("fast", func(s string) {
+		received <- s
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetDispatchMode("slow", DispatchSerialPerMethod)
+	server.SetDispatchMode("fast", DispatchSerialPerMethod)
+
+	for _, m := range []string{"slow", "slow", "fast", "fast"} {
+		if err := client.Notify(m, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The fast notifications are not blocked by the slow handler.
+	for i := 0; i < 2; i++ {
+		if got := <-received; got != "fast" {
+			t.Fatalf("got %q, want %q", got, "fast")
+		}
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		if got := <-received; got != "slow" {
+			t.Fatalf("got %q, want %q", got, "slow")
+		}
+	}
+}
+
+func TestUnregisterAndFallback(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	for _, m := range []string{"b", "a", "c"} {
+		if err := server.Register(m, func() (string, error) { return "registered", nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.Unregister("c")
+
+	if got, want := server.Methods(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Methods() = %v, want %v", got, want)
+	}
+
+	var result string
+	if err := client.Call("c", &result); err == nil {
+		t.Error("call to unregistered method succeeded, want error")
+	}
+
+	server.SetFallbackHandler(func(ctx context.Context, method string, args []msgpack.RawMessage) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("got %d args, want 2", len(args))
+		}
+		var s string
+		var n int
+		if err := msgpack.Unmarshal(args[0], &s); err != nil {
+			return nil, err
+		}
+		if err := msgpack.Unmarshal(args[1], &n); err != nil {
+			return nil, err
+		}
+		return fmt.Sprintf("fallback %s %s %d", method, s, n+1), nil
+	})
+
+	if err := client.Call("c", &result, "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := "fallback c x 2"; result != want {
+		t.Errorf("c returned %q, want %q", result, want)
+	}
+
+	if err := client.Call("a", &result); err != nil {
+		t.Fatal(err)
+	}
+	if want := "registered"; result != want {
+		t.Errorf("a returned %q, want %q", result, want)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if err := server.Register("block", func() error {
+		close(started)
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("ping", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	call := client.Go("block", nil, nil)
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- server.Shutdown(context.Background()) }()
+
+	// Wait for the server to reject new requests.
+	for i := 0; ; i++ {
+		err := client.Call("ping", nil)
+		if err != nil {
+			if !strings.Contains(err.Error(), "shutting down") {
+				t.Fatalf("ping returned %v, want shutting down error", err)
+			}
+			break
+		}
+		if i == 100 {
+			t.Fatal("server did not start shutting down")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned %v before the running request completed", err)
+	default:
+	}
+
+	close(release)
+	if c := <-call.Done; c.Err != nil {
+		t.Fatalf("block returned %v", c.Err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown returned %v", err)
+	}
+}
+
+func TestShutdownContext(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	if err := server.Register("block", func() error {
+		close(started)
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	call := client.Go("block", nil, nil)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown returned %v, want %v", err, context.DeadlineExceeded)
+	}
+	if c := <-call.Done; c.Err == nil {
+		t.Fatal("block succeeded after server closed, want error")
+	}
+}
+
+func TestRawMessageArgs(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	if err := server.Register("raw", func(raw msgpack.RawMessage) (msgpack.RawMessage, error) {
+		var a []int
+		if err := msgpack.Unmarshal(raw, &a); err != nil {
+			return nil, err
+		}
+		if len(a) != 2 || a[0] != 1 || a[1] != 2 {
+			return nil, fmt.Errorf("unexpected arg %v", a)
+		}
+		return raw, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var reply msgpack.RawMessage
+	if err := client.Call("raw", &reply, []int{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := []byte(reply), []byte{0x92, 0x01, 0x02}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reply = %x, want %x", got, want)
+	}
+}
+
+func TestDecoderConfig(t *testing.T) {
+	client, server, cleanup := clientServer(t, WithDecoderConfig(func(d *msgpack.Decoder) {
+		d.DisallowUnknownFields()
+	}))
+	defer cleanup()
+
+	type arg struct{ A int }
+	if err := server.Register("f", func(a arg) (int, error) {
+		return a.A, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register("n", func(a arg) {
+		t.Errorf("notification handler called with invalid argument %v", a)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server ignores notifications with invalid arguments.
+	if err := client.Notify("n", map[string]int{"A": 1, "B": 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	var result int
+	if err := client.Call("f", &result, map[string]int{"A": 1, "B": 2}); err == nil {
+		t.Error("call with unknown field succeeded, want error")
+	}
+	if err := client.Call("f", &result, map[string]int{"A": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if result != 1 {
+		t.Errorf("result = %d, want 1", result)
+	}
+}
+
+func TestEncoderConfig(t *testing.T) {
+	client, server, cleanup := clientServer(t, WithEncoderConfig(func(e *msgpack.Encoder) {
+		e.SetSortMapKeys(true)
+	}))
+	defer cleanup()
+
+	if err := server.Register("f", func(m msgpack.RawMessage) (string, error) {
+		return hex.EncodeToString(m), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var result string
+	if err := client.Call("f", &result, map[string]int{"c": 3, "a": 1, "b": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "83a16101a16202a16303"; result != want {
+		t.Errorf("result = %s, want %s", result, want)
+	}
+}
+
 func TestExtraArgs(t *testing.T) {
 	client, server, cleanup := clientServer(t)
 	defer cleanup()
@@ -0,0 +1,58 @@
+package rpc
+
+import "context"
+
+// ClientInterceptor intercepts calls and notifications sent to the peer by
+// Call, CallContext and Notify. The interceptor must call invoke to send the
+// message and wait for the result. The reply argument is nil for
+// notifications.
+//
+// An interceptor can measure the duration of the call by timing the call to
+// invoke, translate the error returned from invoke, or return an error without
+// calling invoke to prevent the message from being sent.
+type ClientInterceptor func(ctx context.Context, method string, reply interface{}, args []interface{}, invoke func() error) error
+
+// ServerInterceptor intercepts requests and notifications received from the
+// peer. The args argument contains the decoded arguments passed from the
+// peer. The interceptor must call handle to run the registered handler. The
+// result and error returned from the interceptor are sent to the peer as the
+// reply. The result is discarded for notifications.
+type ServerInterceptor func(ctx context.Context, method string, args []interface{}, handle func() (interface{}, error)) (interface{}, error)
+
+// WithClientInterceptor adds interceptors for calls and notifications sent to
+// the peer. The first interceptor is the outermost.
+func WithClientInterceptor(interceptors ...ClientInterceptor) Option {
+	return Option{func(e *Endpoint) {
+		e.clientInterceptors = append(e.clientInterceptors, interceptors...)
+	}}
+}
+
+// WithServerInterceptor adds interceptors for requests and notifications
+// received from the peer. The first interceptor is the outermost.
+func WithServerInterceptor(interceptors ...ServerInterceptor) Option {
+	return Option{func(e *Endpoint) {
+		e.serverInterceptors = append(e.serverInterceptors, interceptors...)
+	}}
+}
+
+func (e *Endpoint) interceptCall(ctx context.Context, method string, reply interface{}, args []interface{}, invoke func() error) error {
+	interceptors := e.clientInterceptors
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoke
+		invoke = func() error {
+			return interceptor(ctx, method, reply, args, next)
+		}
+	}
+	return invoke()
+}
+
+func (e *Endpoint) interceptHandler(method string, args []interface{}, handle func() (interface{}, error)) (interface{}, error) {
+	interceptors := e.serverInterceptors
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handle
+		handle = func() (interface{}, error) {
+			return interceptor(e.ctx, method, args, next)
+		}
+	}
+	return handle()
+}
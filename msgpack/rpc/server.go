@@ -0,0 +1,273 @@
+package rpc
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrServerClosed is returned by the Server's Serve method after a call to
+// Close.
+var ErrServerClosed = errors.New("msgpack/rpc: server closed")
+
+// Server accepts connections from listeners and serves each connection with
+// an Endpoint. Handlers registered with the server are shared by the
+// endpoints for all connections.
+type Server struct {
+	options      []Option
+	onConnect    func(*Endpoint)
+	onDisconnect func(*Endpoint, error)
+
+	mu        sync.Mutex
+	handlers  map[string]*handler
+	listeners map[net.Listener]struct{}
+	endpoints map[*Endpoint]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// ServerOption specifies an option for a server.
+type ServerOption struct{ f func(*Server) }
+
+// WithEndpointOptions specifies the options used to create the endpoint for
+// each connection.
+func WithEndpointOptions(options ...Option) ServerOption {
+	return ServerOption{func(s *Server) {
+		s.options = append(s.options, options...)
+	}}
+}
+
+// WithOnConnect specifies a function called with the endpoint for a new
+// connection. The function is called before the endpoint starts serving the
+// connection and can be used to configure the endpoint. Calls to the peer
+// must be made from a separate goroutine.
+func WithOnConnect(f func(e *Endpoint)) ServerOption {
+	return ServerOption{func(s *Server) {
+		s.onConnect = f
+	}}
+}
+
+// WithOnDisconnect specifies a function called after the endpoint for a
+// connection stops serving. The err argument is the error returned from the
+// endpoint's Serve method.
+func WithOnDisconnect(f func(e *Endpoint, err error)) ServerOption {
+	return ServerOption{func(s *Server) {
+		s.onDisconnect = f
+	}}
+}
+
+// NewServer returns a new server with the specified options.
+func NewServer(options ...ServerOption) *Server {
+	s := &Server{
+		handlers:  make(map[string]*handler),
+		listeners: make(map[net.Listener]struct{}),
+		endpoints: make(map[*Endpoint]struct{}),
+	}
+	for _, option := range options {
+		option.f(s)
+	}
+	return s
+}
+
+// Register registers handler fn for the specified method name on the
+// endpoints for all current and future connections. See Endpoint.Register for
+// a description of fn and args.
+func (s *Server) Register(method string, fn interface{}, args ...interface{}) error {
+	h, err := newHandler(fn, args)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = h
+	for e := range s.endpoints {
+		e.handlersMu.Lock()
+		e.handlers[method] = h
+		e.handlersMu.Unlock()
+	}
+	return nil
+}
+
+// Unregister removes the handler for the specified method name from the
+// endpoints for all connections. Handlers registered directly with an
+// endpoint are not removed.
+func (s *Server) Unregister(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.handlers[method]
+	if !ok {
+		return
+	}
+	delete(s.handlers, method)
+	for e := range s.endpoints {
+		e.handlersMu.Lock()
+		if e.handlers[method] == h {
+			delete(e.handlers, method)
+		}
+		e.handlersMu.Unlock()
+	}
+}
+
+// Endpoints returns the endpoints for the current connections.
+func (s *Server) Endpoints() []*Endpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	endpoints := make([]*Endpoint, 0, len(s.endpoints))
+	for e := range s.endpoints {
+		endpoints = append(endpoints, e)
+	}
+	return endpoints
+}
+
+// Methods returns the sorted names of the methods registered with the server.
+func (s *Server) Methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	methods := make([]string, 0, len(s.handlers))
+	for method := range s.handlers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Broadcast sends a notification to the peers on all current connections.
+// Broadcast returns the first error encountered, if any, after attempting to
+// notify all peers.
+func (s *Server) Broadcast(method string, args ...interface{}) error {
+	var err error
+	for _, e := range s.Endpoints() {
+		if errNotify := e.Notify(method, args...); errNotify != nil && err == nil {
+			err = errNotify
+		}
+	}
+	return err
+}
+
+// Serve accepts connections from l and serves each connection in a new
+// goroutine. Serve retries Accept with increasing delays after timeouts,
+// aborted connections and errors caused by running out of file descriptors or
+// memory. Serve returns other errors from Accept. Serve always returns a
+// non-nil error. After Close, the returned error is ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, l)
+		s.mu.Unlock()
+	}()
+
+	var delay time.Duration
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			if isRetryableAcceptError(err) {
+				if delay == 0 {
+					delay = 5 * time.Millisecond
+				} else if delay *= 2; delay > time.Second {
+					delay = time.Second
+				}
+				time.Sleep(delay)
+				continue
+			}
+			return err
+		}
+		delay = 0
+
+		if err := s.serveConn(conn); err != nil {
+			conn.Close()
+			if err == ErrServerClosed {
+				return err
+			}
+		}
+	}
+}
+
+// isRetryableAcceptError reports whether err is an Accept error that does not
+// prevent accepting later connections.
+func isRetryableAcceptError(err error) bool {
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return true
+	}
+	if oe, ok := err.(*net.OpError); ok {
+		err = oe.Err
+	}
+	if se, ok := err.(*os.SyscallError); ok {
+		err = se.Err
+	}
+	errno, ok := err.(syscall.Errno)
+	return ok && retryableAcceptErrno(errno)
+}
+
+func (s *Server) serveConn(conn net.Conn) error {
+	e, err := NewEndpoint(conn, conn, conn, s.options...)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	for method, h := range s.handlers {
+		e.handlers[method] = h
+	}
+	s.endpoints[e] = struct{}{}
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		if s.onConnect != nil {
+			s.onConnect(e)
+		}
+		err := e.Serve()
+		e.Close()
+
+		s.mu.Lock()
+		delete(s.endpoints, e)
+		s.mu.Unlock()
+
+		if s.onDisconnect != nil {
+			s.onDisconnect(e, err)
+		}
+	}()
+	return nil
+}
+
+// Close closes the listeners and the endpoints for all connections. Close
+// waits for the disconnect callbacks to return.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	for l := range s.listeners {
+		if errClose := l.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+	}
+	for e := range s.endpoints {
+		e.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
@@ -0,0 +1,237 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"reflect"
+	"runtime"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+func dialServer(t *testing.T, addr string) (*Endpoint, func()) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewEndpoint(conn, conn, conn, WithLogf(t.Logf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		client.Serve()
+		close(done)
+	}()
+	return client, func() {
+		client.Close()
+		<-done
+	}
+}
+
+func TestServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connected := make(chan *Endpoint, 2)
+	disconnected := make(chan *Endpoint, 2)
+	s := NewServer(
+		WithEndpointOptions(WithLogf(t.Logf)),
+		WithOnConnect(func(e *Endpoint) { connected <- e }),
+		WithOnDisconnect(func(e *Endpoint, err error) { disconnected <- e }),
+	)
+
+	if err := s.Register("self", func(ctx context.Context) (bool, error) {
+		e, ok := EndpointFromContext(ctx)
+		if !ok {
+			return false, errors.New("no endpoint in context")
+		}
+		for _, se := range s.Endpoints() {
+			if se == e {
+				return true, nil
+			}
+		}
+		return false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- s.Serve(l) }()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var received []string
+
+	for i := 0; i < 2; i++ {
+		client, cleanup := dialServer(t, l.Addr().String())
+		defer cleanup()
+		<-connected
+
+		wg.Add(1)
+		if err := client.Register("hello", func(msg string) {
+			mu.Lock()
+			received = append(received, msg)
+			mu.Unlock()
+			wg.Done()
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		var ok bool
+		if err := client.Call("self", &ok); err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("handler context does not contain the endpoint for the connection")
+		}
+	}
+
+	if err := s.Register("late", func() (string, error) { return "late", nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Methods(), []string{"late", "self"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Methods() = %v, want %v", got, want)
+	}
+	for _, e := range s.Endpoints() {
+		if got, want := e.Methods(), []string{"late", "self"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("endpoint Methods() = %v, want %v", got, want)
+		}
+	}
+
+	// Unregister removes only the handlers installed by the server.
+	if err := s.Endpoints()[0].Register("self", func() (bool, error) { return true, nil }); err != nil {
+		t.Fatal(err)
+	}
+	s.Unregister("self")
+	s.Unregister("late")
+	if got := s.Methods(); len(got) != 0 {
+		t.Errorf("Methods() after Unregister = %v, want none", got)
+	}
+	var own int
+	for _, e := range s.Endpoints() {
+		switch got := e.Methods(); {
+		case reflect.DeepEqual(got, []string{"self"}):
+			own++
+		case len(got) != 0:
+			t.Errorf("endpoint Methods() after Unregister = %v", got)
+		}
+	}
+	if own != 1 {
+		t.Errorf("%d endpoints kept their own handler after Unregister, want 1", own)
+	}
+
+	if err := s.Broadcast("hello", "world"); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if want := []string{"world", "world"}; !reflect.DeepEqual(received, want) {
+		t.Errorf("received %v, want %v", received, want)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-serveErr; err != ErrServerClosed {
+		t.Errorf("Serve returned %v, want %v", err, ErrServerClosed)
+	}
+	for i := 0; i < 2; i++ {
+		<-disconnected
+	}
+	if n := len(s.Endpoints()); n != 0 {
+		t.Errorf("server has %d endpoints after Close, want 0", n)
+	}
+}
+
+// errListener returns errs from Accept before accepting connections from the
+// embedded listener.
+type errListener struct {
+	net.Listener
+	errs []error
+}
+
+func (l *errListener) Accept() (net.Conn, error) {
+	if len(l.errs) > 0 {
+		err := l.errs[0]
+		l.errs = l.errs[1:]
+		return nil, err
+	}
+	return l.Listener.Accept()
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestServerAcceptErrors(t *testing.T) {
+	if runtime.GOOS == "plan9" {
+		t.Skip("Accept errors are not reported with an Errno on plan9")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connected := make(chan *Endpoint, 1)
+	s := NewServer(
+		WithEndpointOptions(WithLogf(t.Logf)),
+		WithOnConnect(func(e *Endpoint) { connected <- e }),
+	)
+	defer s.Close()
+
+	// Serve retries after errors that do not affect later connections.
+	retryable := &errListener{Listener: l, errs: []error{
+		&net.OpError{Op: "accept", Net: "tcp", Err: os.NewSyscallError("accept", syscall.EMFILE)},
+		&net.OpError{Op: "accept", Net: "tcp", Err: timeoutError{}},
+	}}
+	go s.Serve(retryable)
+	_, cleanup := dialServer(t, l.Addr().String())
+	defer cleanup()
+	<-connected
+
+	// Serve returns other errors.
+	errPermanent := errors.New("permanent")
+	if err := s.Serve(&errListener{errs: []error{errPermanent}}); err != errPermanent {
+		t.Errorf("Serve returned %v, want %v", err, errPermanent)
+	}
+}
+
+func TestServerUnknownMethod(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server's endpoints have no logger.
+	s := NewServer()
+	defer s.Close()
+	if err := s.Register("ping", func() (string, error) { return "pong", nil }); err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+
+	client, cleanup := dialServer(t, l.Addr().String())
+	defer cleanup()
+
+	if err := client.Call("unknown", nil); err == nil {
+		t.Error("call of unknown method returned nil error")
+	}
+	if err := client.Notify("unknown"); err != nil {
+		t.Fatal(err)
+	}
+	var reply string
+	if err := client.Call("ping", &reply); err != nil {
+		t.Fatalf("call after unknown method returned error %v", err)
+	}
+	if reply != "pong" {
+		t.Errorf("ping returned %q, want %q", reply, "pong")
+	}
+}
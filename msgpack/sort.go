@@ -0,0 +1,215 @@
+package msgpack
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SetSortMapKeys specifies whether the encoder sorts the keys of Go maps.
+// Sorting the keys makes the encoding of a value deterministic.
+//
+// String keys are sorted by value and numeric keys are sorted by numeric
+// value. Other keys are sorted by their MessagePack encoding. When keys have
+// different types, as in a map[interface{}]interface{}, nil sorts before
+// booleans, booleans before numbers, numbers before strings and strings
+// before all other values.
+func (e *Encoder) SetSortMapKeys(sort bool) {
+	e.sortMapKeys = sort
+}
+
+// SortMapKeys returns whether the encoder sorts the keys of Go maps. See
+// SetSortMapKeys for details.
+func (e *Encoder) SortMapKeys() bool {
+	return e.sortMapKeys
+}
+
+// configuredEncoder returns a new encoder that writes to w with the same
+// configuration as e.
+func (e *Encoder) configuredEncoder(w io.Writer) *Encoder {
+	ce := NewEncoder(w)
+	ce.sortMapKeys = e.sortMapKeys
+	ce.extensions = e.extensions
+	ce.fieldOptions = e.fieldOptions
+	return ce
+}
+
+// Ranks of map keys with different types.
+const (
+	nilRank = iota
+	boolRank
+	numberRank
+	stringRank
+	otherRank
+)
+
+type sortKey struct {
+	// v is the map key.
+	v reflect.Value
+	// k is the map key with interfaces removed.
+	k    reflect.Value
+	rank int
+	// p is the encoding of the key. The encoding is created on first use.
+	p []byte
+}
+
+// sortMapKeys sorts map keys using the order described in SetSortMapKeys.
+// The function encodeKey is used to encode keys that are sorted by encoding.
+// The keys are encoded with the configuration of e so that the keys are
+// sorted by the encoding written by e.
+func sortMapKeys(e *Encoder, keys []reflect.Value, encodeKey encodeFunc) {
+	sks := make([]*sortKey, len(keys))
+	for i, v := range keys {
+		sk := &sortKey{v: v, k: v}
+		for sk.k.Kind() == reflect.Interface && !sk.k.IsNil() {
+			sk.k = sk.k.Elem()
+		}
+		switch sk.k.Kind() {
+		case reflect.Interface:
+			sk.rank = nilRank
+		case reflect.Bool:
+			sk.rank = boolRank
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			sk.rank = numberRank
+		case reflect.String:
+			sk.rank = stringRank
+		default:
+			sk.rank = otherRank
+		}
+		sks[i] = sk
+	}
+
+	encoding := func(sk *sortKey) []byte {
+		if sk.p == nil {
+			var buf bytes.Buffer
+			encodeKey(e.configuredEncoder(&buf), sk.v)
+			sk.p = buf.Bytes()
+		}
+		return sk.p
+	}
+
+	sort.Slice(sks, func(i, j int) bool {
+		a, b := sks[i], sks[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		var c int
+		switch a.rank {
+		case boolRank:
+			c = compareBools(a.k.Bool(), b.k.Bool())
+		case numberRank:
+			c = compareNumbers(a.k, b.k)
+		case stringRank:
+			c = strings.Compare(a.k.String(), b.k.String())
+		}
+		if c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(encoding(a), encoding(b)) < 0
+	})
+
+	for i, sk := range sks {
+		keys[i] = sk.v
+	}
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// compareNumbers compares numeric values of any kind.
+func compareNumbers(a, b reflect.Value) int {
+	ak, bk := numberKind(a), numberKind(b)
+	switch {
+	case ak == reflect.Int && bk == reflect.Int:
+		return compareInts(a.Int(), b.Int())
+	case ak == reflect.Uint && bk == reflect.Uint:
+		return compareUints(a.Uint(), b.Uint())
+	case ak == reflect.Int && bk == reflect.Uint:
+		if a.Int() < 0 {
+			return -1
+		}
+		return compareUints(uint64(a.Int()), b.Uint())
+	case ak == reflect.Uint && bk == reflect.Int:
+		return -compareNumbers(b, a)
+	default:
+		return compareFloats(numberFloat(a), numberFloat(b))
+	}
+}
+
+// numberKind returns reflect.Int, reflect.Uint or reflect.Float64 for a
+// numeric value.
+func numberKind(v reflect.Value) reflect.Kind {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	default:
+		return reflect.Float64
+	}
+}
+
+func numberFloat(v reflect.Value) float64 {
+	switch numberKind(v) {
+	case reflect.Int:
+		return float64(v.Int())
+	case reflect.Uint:
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareFloats compares floats. NaN values sort before other values.
+func compareFloats(a, b float64) int {
+	an, bn := math.IsNaN(a), math.IsNaN(b)
+	switch {
+	case an && bn:
+		return 0
+	case an:
+		return -1
+	case bn:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
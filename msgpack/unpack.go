@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"reflect"
+	"time"
 )
 
 // Type represents the type of value in the MsgPack stream.
@@ -55,11 +57,59 @@ func (t Type) String() string {
 type Decoder struct {
 	extensions ExtensionMap
 	err        error
-	r          *bufio.Reader
+	r          reader
 	n          uint64
 	p          []byte
 	t          Type
+	code       byte
 	peek       bool
+
+	disallowUnknownFields bool
+	strictNumbers         bool
+	fieldOptions          FieldOptions
+
+	// useNumber, useInt64, binaryAsString and mapType configure the
+	// decoding of values to interface values.
+	useNumber      bool
+	useInt64       bool
+	binaryAsString bool
+	mapType        reflect.Type
+
+	limits Limits
+
+	// typeExtensions and kindExtensions hold the extensions registered
+	// with RegisterExtension.
+	typeExtensions map[reflect.Type]*TypeExtension
+	kindExtensions map[int]*TypeExtension
+
+	// stack holds the number of values remaining in each of the arrays and
+	// maps containing the current value.
+	stack []uint64
+
+	// valueBytes is the number of bytes read for the current top-level
+	// value.
+	valueBytes uint64
+
+	// offset is the number of bytes read from the input. valueOffset is the
+	// offset of the current value.
+	offset      int64
+	valueOffset int64
+
+	// peekMax is the maximum size of a value returned from r.Peek.
+	peekMax int
+
+	// hdr is the encoding of the current value excluding the String, Binary
+	// or Extension data.
+	hdr    []byte
+	hdrBuf [10]byte
+}
+
+// reader is the interface implemented by *bufio.Reader and *bytesReader.
+type reader interface {
+	io.Reader
+	io.ByteReader
+	Peek(n int) ([]byte, error)
+	Discard(n int) (int, error)
 }
 
 const bufioReaderSize = 4096
@@ -67,7 +117,17 @@ const bufioReaderSize = 4096
 // NewDecoder allocates and initializes a new decoder.
 func NewDecoder(r io.Reader) *Decoder {
 	return &Decoder{
-		r: bufio.NewReaderSize(r, bufioReaderSize),
+		r:       bufio.NewReaderSize(r, bufioReaderSize),
+		peekMax: bufioReaderSize,
+	}
+}
+
+// newBytesDecoder returns a decoder that reads from p without copying p to
+// an intermediate buffer.
+func newBytesDecoder(p []byte) *Decoder {
+	return &Decoder{
+		r:       &bytesReader{p: p},
+		peekMax: math.MaxInt32,
 	}
 }
 
@@ -82,6 +142,108 @@ func (d *Decoder) SetExtensions(extensions ExtensionMap) {
 	d.extensions = extensions
 }
 
+// DisallowUnknownFields causes Decode to return an UnknownFieldError when the
+// destination is a struct and the input contains map keys that do not match
+// any non-ignored, exported fields in the destination.
+func (d *Decoder) DisallowUnknownFields() {
+	d.disallowUnknownFields = true
+}
+
+// UnknownFieldsDisallowed returns whether DisallowUnknownFields was called.
+func (d *Decoder) UnknownFieldsDisallowed() bool {
+	return d.disallowUnknownFields
+}
+
+// UseStrictNumbers causes Decode to return a DecodeConvertError when a number
+// is converted to a Go value of a different kind. With strict numbers, Float
+// values cannot be decoded to integers, Int and Uint values cannot be decoded
+// to booleans and 64-bit Float values that cannot be represented exactly as a
+// float32 cannot be decoded to a float32.
+func (d *Decoder) UseStrictNumbers() {
+	d.strictNumbers = true
+}
+
+// UseNumber causes Decode to store Int, Uint and Float values in interface
+// values as a Number instead of an int64, uint64 or float64.
+func (d *Decoder) UseNumber() {
+	d.useNumber = true
+}
+
+// UseInt64 causes Decode to store Uint values in interface values as an int64
+// instead of an uint64. Decode returns a DecodeConvertError for Uint values
+// that overflow an int64.
+func (d *Decoder) UseInt64() {
+	d.useInt64 = true
+}
+
+// UseBinaryAsString causes Decode to store Binary values in interface values
+// as a string instead of a []byte.
+func (d *Decoder) UseBinaryAsString() {
+	d.binaryAsString = true
+}
+
+// SetMapType sets the type of the maps that Decode stores in interface
+// values. The underlying type of t must be map[string]interface{} or
+// map[interface{}]interface{}. The default is map[string]interface{}. Map
+// keys that cannot be stored in the map, such as arrays in maps with string
+// keys, are skipped and reported with a DecodeConvertError.
+func (d *Decoder) SetMapType(t reflect.Type) {
+	if t.Kind() != reflect.Map || t.Elem() != emptyInterfaceType || (t.Key() != stringType && t.Key() != emptyInterfaceType) {
+		panic("msgpack: invalid map type " + t.String())
+	}
+	d.mapType = t
+}
+
+// Limits specifies limits on the values read by a Decoder. A limit with the
+// value zero is not checked.
+type Limits struct {
+	// MaxStringLen is the maximum length of String, Binary and Extension
+	// data.
+	MaxStringLen int
+
+	// MaxArrayLen is the maximum length of an array.
+	MaxArrayLen int
+
+	// MaxMapLen is the maximum number of key-value pairs in a map.
+	MaxMapLen int
+
+	// MaxValueBytes is the maximum number of bytes in a top-level value,
+	// including all nested values. Each element of an array or map is
+	// counted as at least one byte when the array or map length is read.
+	MaxValueBytes int
+
+	// MaxDepth is the maximum nesting depth of arrays and maps.
+	MaxDepth int
+}
+
+// SetLimits sets limits on the values read by the decoder. Unpack returns a
+// *LimitError when a value exceeds a limit. The error stops the decoder.
+func (d *Decoder) SetLimits(limits Limits) {
+	d.limits = limits
+}
+
+// LimitError is returned when a value in the input exceeds a limit set with
+// SetLimits.
+type LimitError struct {
+	// Limit is the name of the field in Limits.
+	Limit string
+	// Value is the length, size or depth that exceeds the limit.
+	Value uint64
+	// Max is the value of the limit.
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("msgpack: %d exceeds limit %s %d", e.Value, e.Limit, e.Max)
+}
+
+func checkLimit(limit string, v uint64, max int) error {
+	if max > 0 && v > uint64(max) {
+		return &LimitError{Limit: limit, Value: v, Max: max}
+	}
+	return nil
+}
+
 // Type returns the type of the current value in the stream.
 func (d *Decoder) Type() Type {
 	return d.t
@@ -143,6 +305,42 @@ func (d *Decoder) Float() float64 {
 	return math.Float64frombits(d.n)
 }
 
+// IsFloat32 returns whether the current Float value was encoded as a 32-bit
+// float.
+func (d *Decoder) IsFloat32() bool {
+	return d.t == Float && d.code == float32Code
+}
+
+// IsTimestamp returns whether the current value is a timestamp extension.
+func (d *Decoder) IsTimestamp() bool {
+	return d.t == Extension && int8(d.n) == timestampExtension
+}
+
+// Timestamp returns the current timestamp extension value. The returned time
+// is in the local time zone.
+func (d *Decoder) Timestamp() (time.Time, error) {
+	if !d.IsTimestamp() {
+		return time.Time{}, fmt.Errorf("msgpack: %s is not a timestamp", d.t)
+	}
+	p := d.p
+	switch len(p) {
+	case 4:
+		sec := uint32(p[3]) | uint32(p[2])<<8 | uint32(p[1])<<16 | uint32(p[0])<<24
+		return time.Unix(int64(sec), 0), nil
+	case 8:
+		n := uint64(p[7]) | uint64(p[6])<<8 | uint64(p[5])<<16 | uint64(p[4])<<24 |
+			uint64(p[3])<<32 | uint64(p[2])<<40 | uint64(p[1])<<48 | uint64(p[0])<<56
+		return time.Unix(int64(n&(1<<34-1)), int64(n>>34)), nil
+	case 12:
+		nsec := uint32(p[3]) | uint32(p[2])<<8 | uint32(p[1])<<16 | uint32(p[0])<<24
+		sec := uint64(p[11]) | uint64(p[10])<<8 | uint64(p[9])<<16 | uint64(p[8])<<24 |
+			uint64(p[7])<<32 | uint64(p[6])<<40 | uint64(p[5])<<48 | uint64(p[4])<<56
+		return time.Unix(int64(sec), int64(nsec)), nil
+	default:
+		return time.Time{}, fmt.Errorf("msgpack: invalid timestamp length %d", len(p))
+	}
+}
+
 // Unpack reads the next value from the MsgPack stream. Call Type to get the
 // type of the current value. Call Bool, Uint, Int, Float, Bytes or Extension
 // to get the value.
@@ -158,16 +356,79 @@ func (d *Decoder) Unpack() error {
 		d.err = err
 		return err
 	}
+
+	// Pop finished arrays and maps and count the current value in the
+	// containing array or map.
+	for len(d.stack) > 0 && d.stack[len(d.stack)-1] == 0 {
+		d.stack = d.stack[:len(d.stack)-1]
+	}
+	nested := len(d.stack) > 0
+	if !nested {
+		d.valueBytes = 0
+	} else {
+		d.stack[len(d.stack)-1]--
+	}
+
 	f := formats[code]
 	d.t = f.t
+	d.code = code
+	d.hdr = append(d.hdrBuf[:0], code)
+	d.valueOffset = d.offset
 
 	d.n, err = f.n(d, code)
 	if err != nil {
 		return d.fatal(err)
 	}
 
+	switch f.t {
+	case ArrayLen:
+		err = checkLimit("MaxArrayLen", d.n, d.limits.MaxArrayLen)
+		d.stack = append(d.stack, d.n)
+	case MapLen:
+		err = checkLimit("MaxMapLen", d.n, d.limits.MaxMapLen)
+		d.stack = append(d.stack, 2*d.n)
+	case String, Binary, Extension:
+		err = checkLimit("MaxStringLen", d.n, d.limits.MaxStringLen)
+	}
+	if err == nil {
+		err = checkLimit("MaxDepth", uint64(len(d.stack)), d.limits.MaxDepth)
+	}
+	// need is the minimum number of bytes following the header: the data
+	// and the extension type, or at least one byte for each array element
+	// and map key and value.
+	var need uint64
+	switch {
+	case f.more:
+		need = d.n
+		if f.t == Extension {
+			need++
+		}
+	case f.t == ArrayLen:
+		need = d.n
+	case f.t == MapLen:
+		need = 2 * d.n
+	}
+	if err == nil {
+		n := uint64(len(d.hdr)) + need
+		if nested {
+			// The containing array or map counted one byte for this
+			// value.
+			n--
+		}
+		d.valueBytes += n
+		err = checkLimit("MaxValueBytes", d.valueBytes, d.limits.MaxValueBytes)
+	}
+	if r, ok := d.r.(*bytesReader); ok && err == nil && need > uint64(len(r.p)-r.off) {
+		// Don't allocate for lengths larger than the remaining input.
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return d.fatal(err)
+	}
+
 	if !f.more {
 		d.p = nil
+		d.offset += int64(len(d.hdr))
 		return nil
 	}
 
@@ -182,10 +443,11 @@ func (d *Decoder) Unpack() error {
 		if err != nil {
 			return d.fatal(err)
 		}
+		d.hdr = append(d.hdr, b)
 		d.n = uint64(b)
 	}
 
-	if nn <= bufioReaderSize {
+	if nn <= d.peekMax {
 		d.peek = true
 		d.p, err = d.r.Peek(nn)
 		if err != nil {
@@ -201,6 +463,7 @@ func (d *Decoder) Unpack() error {
 		}
 	}
 
+	d.offset += int64(len(d.hdr) + nn)
 	return nil
 }
 
@@ -217,6 +480,116 @@ func (d *Decoder) Skip() error {
 	return nil
 }
 
+// Peek returns the type of the next value in the stream without reading the
+// value. Like Unpack, Peek may overwrite the data returned by BytesNoCopy.
+func (d *Decoder) Peek() (Type, error) {
+	if d.err != nil {
+		return Invalid, d.err
+	}
+	p, err := d.r.Peek(1)
+	if err != nil {
+		return Invalid, err
+	}
+	return formats[p[0]].t, nil
+}
+
+// Depth returns the number of arrays and maps that contain the next value in
+// the stream.
+func (d *Decoder) Depth() int {
+	n := len(d.stack)
+	for n > 0 && d.stack[n-1] == 0 {
+		n--
+	}
+	return n
+}
+
+// SkipValue reads the next value in the stream including any nested values.
+func (d *Decoder) SkipValue() error {
+	if err := d.Unpack(); err != nil {
+		return err
+	}
+	return d.Skip()
+}
+
+// The Read methods read the next value in the stream. If the value does not
+// have the expected type, the Read methods skip the value and return a
+// *DecodeConvertError. The Read methods convert values using the same rules
+// as Decode.
+
+// ReadArrayHeader reads the header of an array and returns the number of
+// elements in the array. The application must read the elements from the
+// stream following this call.
+func (d *Decoder) ReadArrayHeader() (int, error) {
+	if err := d.Unpack(); err != nil {
+		return 0, err
+	}
+	if d.t != ArrayLen {
+		return 0, d.convertError(interfaceSliceType, nil)
+	}
+	return d.Len(), nil
+}
+
+// ReadMapHeader reads the header of a map and returns the number of
+// key-value pairs in the map. The application must read the keys and values
+// from the stream following this call.
+func (d *Decoder) ReadMapHeader() (int, error) {
+	if err := d.Unpack(); err != nil {
+		return 0, err
+	}
+	if d.t != MapLen {
+		return 0, d.convertError(interfaceMapType, nil)
+	}
+	return d.Len(), nil
+}
+
+// ReadBool reads a bool.
+func (d *Decoder) ReadBool() (bool, error) {
+	if err := d.Unpack(); err != nil {
+		return false, err
+	}
+	return d.ConvertBool()
+}
+
+// ReadInt reads an int64.
+func (d *Decoder) ReadInt() (int64, error) {
+	if err := d.Unpack(); err != nil {
+		return 0, err
+	}
+	return d.ConvertInt(64)
+}
+
+// ReadUint reads a uint64.
+func (d *Decoder) ReadUint() (uint64, error) {
+	if err := d.Unpack(); err != nil {
+		return 0, err
+	}
+	return d.ConvertUint(64)
+}
+
+// ReadFloat reads a float64.
+func (d *Decoder) ReadFloat() (float64, error) {
+	if err := d.Unpack(); err != nil {
+		return 0, err
+	}
+	return d.ConvertFloat(64)
+}
+
+// ReadString reads a string from a String or Binary value.
+func (d *Decoder) ReadString() (string, error) {
+	if err := d.Unpack(); err != nil {
+		return "", err
+	}
+	return d.ConvertString()
+}
+
+// ReadBytes reads a byte slice from a String, Binary or Nil value.
+func (d *Decoder) ReadBytes() ([]byte, error) {
+	if err := d.Unpack(); err != nil {
+		return nil, err
+	}
+	return d.ConvertBytes()
+}
+
 func (d *Decoder) skipCount() int {
 	switch d.Type() {
 	case ArrayLen:
@@ -441,7 +814,11 @@ func (d *Decoder) fatal(err error) error {
 
 func (d *Decoder) read1(format byte) (uint64, error) {
 	b, err := d.r.ReadByte()
-	return uint64(b), err
+	if err != nil {
+		return 0, err
+	}
+	d.hdr = append(d.hdr, b)
+	return uint64(b), nil
 }
 
 func (d *Decoder) read2(format byte) (uint64, error) {
@@ -449,6 +826,7 @@ func (d *Decoder) read2(format byte) (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
+	d.hdr = append(d.hdr, p...)
 	d.r.Discard(2)
 	return uint64(p[1]) | uint64(p[0])<<8, nil
 }
@@ -458,6 +836,7 @@ func (d *Decoder) read4(format byte) (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
+	d.hdr = append(d.hdr, p...)
 	d.r.Discard(4)
 	return uint64(p[3]) | uint64(p[2])<<8 | uint64(p[1])<<16 | uint64(p[0])<<24, nil
 }
@@ -467,7 +846,67 @@ func (d *Decoder) read8(format byte) (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
+	d.hdr = append(d.hdr, p...)
 	d.r.Discard(8)
 	return uint64(p[7]) | uint64(p[6])<<8 | uint64(p[5])<<16 | uint64(p[4])<<24 |
 		uint64(p[3])<<32 | uint64(p[2])<<40 | uint64(p[1])<<48 | uint64(p[0])<<56, nil
 }
+
+// appendRaw appends the encoding of the current value and any nested values
+// to dst.
+func (d *Decoder) appendRaw(dst []byte) ([]byte, error) {
+	dst = append(dst, d.hdr...)
+	dst = append(dst, d.p...)
+	n := d.skipCount()
+	for n > 0 {
+		n--
+		if err := d.Unpack(); err != nil {
+			return dst, err
+		}
+		dst = append(dst, d.hdr...)
+		dst = append(dst, d.p...)
+		n += d.skipCount()
+	}
+	return dst, nil
+}
+
+// bytesReader reads from a byte slice.
+type bytesReader struct {
+	p   []byte
+	off int
+}
+
+func (r *bytesReader) Read(p []byte) (int, error) {
+	if r.off >= len(r.p) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.p[r.off:])
+	r.off += n
+	return n, nil
+}
+
+func (r *bytesReader) ReadByte() (byte, error) {
+	if r.off >= len(r.p) {
+		return 0, io.EOF
+	}
+	b := r.p[r.off]
+	r.off++
+	return b, nil
+}
+
+func (r *bytesReader) Peek(n int) ([]byte, error) {
+	if n > len(r.p)-r.off {
+		return r.p[r.off:], io.EOF
+	}
+	return r.p[r.off : r.off+n], nil
+}
+
+func (r *bytesReader) Discard(n int) (int, error) {
+	if n > len(r.p)-r.off {
+		n = len(r.p) - r.off
+		r.off += n
+		return n, io.EOF
+	}
+	r.off += n
+	return n, nil
+}
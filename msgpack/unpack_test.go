@@ -174,7 +174,155 @@ func TestUnpackEOF(t *testing.T) {
 				if err != io.ErrUnexpectedEOF {
 					t.Errorf("unpack(%s[:%d]) returned %v, want %v", h, i, err, io.ErrUnexpectedEOF)
 				}
+				d = newBytesDecoder(p[:i])
+				err = d.Unpack()
+				if err != io.ErrUnexpectedEOF {
+					t.Errorf("unpack bytes(%s[:%d]) returned %v, want %v", h, i, err, io.ErrUnexpectedEOF)
+				}
 			}
 		}
 	}
 }
+
+func TestUnpackFloat32(t *testing.T) {
+	for _, tt := range []struct {
+		h       string
+		float32 bool
+	}{
+		{"ca47f12000", true},
+		{"cb3ff3c0c1fc8f3238", false},
+		{"01", false},
+	} {
+		p, err := hex.DecodeString(tt.h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewDecoder(bytes.NewReader(p))
+		if err := d.Unpack(); err != nil {
+			t.Fatalf("unpack(%s) returned %v", tt.h, err)
+		}
+		if d.IsFloat32() != tt.float32 {
+			t.Errorf("unpack(%s) IsFloat32() = %v, want %v", tt.h, d.IsFloat32(), tt.float32)
+		}
+	}
+}
+
+func TestReadTokens(t *testing.T) {
+	// {"a": [1, "x"], "b": true}, {"c": 1}, 5
+	p, err := hex.DecodeString("82a1619201a178a162c381a1630105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(bytes.NewReader(p))
+
+	check := func(what string, got, want interface{}, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s returned error %v", what, err)
+		}
+		if got != want {
+			t.Fatalf("%s = %v, want %v", what, got, want)
+		}
+	}
+	checkDepth := func(want int) {
+		t.Helper()
+		if got := d.Depth(); got != want {
+			t.Fatalf("Depth() = %d, want %d", got, want)
+		}
+	}
+
+	typ, err := d.Peek()
+	check("Peek()", typ, MapLen, err)
+	checkDepth(0)
+	n, err := d.ReadMapHeader()
+	check("ReadMapHeader()", n, 2, err)
+	checkDepth(1)
+	s, err := d.ReadString()
+	check("ReadString()", s, "a", err)
+	typ, err = d.Peek()
+	check("Peek()", typ, ArrayLen, err)
+	n, err = d.ReadArrayHeader()
+	check("ReadArrayHeader()", n, 2, err)
+	checkDepth(2)
+	i, err := d.ReadInt()
+	check("ReadInt()", i, int64(1), err)
+	if _, err := d.ReadInt(); err == nil {
+		t.Fatal("ReadInt() of string did not return error")
+	} else if _, ok := err.(*DecodeConvertError); !ok {
+		t.Fatalf("ReadInt() of string returned error %v, want *DecodeConvertError", err)
+	}
+	checkDepth(1)
+	s, err = d.ReadString()
+	check("ReadString()", s, "b", err)
+	check("SkipValue()", nil, nil, d.SkipValue())
+	checkDepth(0)
+	if _, err := d.ReadArrayHeader(); err == nil {
+		t.Fatal("ReadArrayHeader() of map did not return error")
+	}
+	checkDepth(0)
+	i, err = d.ReadInt()
+	check("ReadInt()", i, int64(5), err)
+	if _, err := d.Peek(); err != io.EOF {
+		t.Fatalf("Peek() at end returned error %v, want %v", err, io.EOF)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	for _, tt := range []struct {
+		h      string
+		limits Limits
+		// limit is the name of the exceeded limit or "" for no error.
+		limit string
+	}{
+		{"a3616263", Limits{MaxStringLen: 3}, ""},
+		{"a3616263", Limits{MaxStringLen: 2}, "MaxStringLen"},
+		{"dbffffffff", Limits{MaxStringLen: 1024}, "MaxStringLen"},
+		{"c403010203", Limits{MaxStringLen: 2}, "MaxStringLen"},
+		{"d7010000000000000000", Limits{MaxStringLen: 4}, "MaxStringLen"},
+		{"93010203", Limits{MaxArrayLen: 3}, ""},
+		{"93010203", Limits{MaxArrayLen: 2}, "MaxArrayLen"},
+		{"ddffffffff", Limits{MaxArrayLen: 1024}, "MaxArrayLen"},
+		{"82010203a0", Limits{MaxMapLen: 2}, ""},
+		{"82010203a0", Limits{MaxMapLen: 1}, "MaxMapLen"},
+		{"919191c0", Limits{MaxDepth: 3}, ""},
+		{"919191c0", Limits{MaxDepth: 2}, "MaxDepth"},
+		{"92919101919101", Limits{MaxDepth: 3}, ""},
+		{"9190919190", Limits{MaxDepth: 3}, ""},
+		{"9190919190", Limits{MaxDepth: 2}, "MaxDepth"},
+		// Limits apply to each top-level value.
+		{"92a161a16292a161a162", Limits{MaxValueBytes: 5}, ""},
+		{"92a161a16292a161a162", Limits{MaxValueBytes: 4}, "MaxValueBytes"},
+		{"c4ff", Limits{MaxValueBytes: 16}, "MaxValueBytes"},
+		{"93010203", Limits{MaxValueBytes: 4}, ""},
+		{"93010203", Limits{MaxValueBytes: 3}, "MaxValueBytes"},
+		{"82010203a0", Limits{MaxValueBytes: 5}, ""},
+		{"82010203a0", Limits{MaxValueBytes: 4}, "MaxValueBytes"},
+		// Declared lengths count against MaxValueBytes before the elements
+		// are read.
+		{"dd7fffffff", Limits{MaxValueBytes: 1 << 20}, "MaxValueBytes"},
+		{"df7fffffff", Limits{MaxValueBytes: 1 << 20}, "MaxValueBytes"},
+	} {
+		p, err := hex.DecodeString(tt.h)
+		if err != nil {
+			t.Errorf("decode(%s) returned error %v", tt.h, err)
+			continue
+		}
+		d := NewDecoder(bytes.NewReader(p))
+		d.SetLimits(tt.limits)
+		for {
+			err = d.Unpack()
+			if err != nil {
+				break
+			}
+		}
+		if tt.limit == "" {
+			if err != io.EOF {
+				t.Errorf("unpack(%s) with %+v returned %v, want %v", tt.h, tt.limits, err, io.EOF)
+			}
+			continue
+		}
+		if e, ok := err.(*LimitError); !ok || e.Limit != tt.limit {
+			t.Errorf("unpack(%s) with %+v returned %v, want %s error", tt.h, tt.limits, err, tt.limit)
+		}
+	}
+}
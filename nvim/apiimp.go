@@ -3,6 +3,7 @@
 package nvim
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/neovim/go-client/msgpack"
@@ -92,6 +93,13 @@ func (v *Nvim) BufferLineCount(buffer Buffer) (int, error) {
 	return result, err
 }
 
+// BufferLineCountContext is like BufferLineCount, but uses ctx to cancel the request.
+func (v *Nvim) BufferLineCountContext(ctx context.Context, buffer Buffer) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_line_count", &result, buffer)
+	return result, err
+}
+
 // BufferLineCount returns the number of lines in the buffer.
 func (b *Batch) BufferLineCount(buffer Buffer, result *int) {
 	b.call("nvim_buf_line_count", result, buffer)
@@ -111,6 +119,13 @@ func (v *Nvim) BufferLines(buffer Buffer, start int, end int, strict bool) ([][]
 	return result, err
 }
 
+// BufferLinesContext is like BufferLines, but uses ctx to cancel the request.
+func (v *Nvim) BufferLinesContext(ctx context.Context, buffer Buffer, start int, end int, strict bool) ([][]byte, error) {
+	var result [][]byte
+	err := v.callContext(ctx, "nvim_buf_get_lines", &result, buffer, start, end, strict)
+	return result, err
+}
+
 // BufferLines retrieves a line range from a buffer.
 //
 // Indexing is zero-based, end-exclusive. Negative indices are interpreted as
@@ -138,6 +153,13 @@ func (v *Nvim) AttachBuffer(buffer Buffer, sendBuffer bool, opts map[string]inte
 	return result, err
 }
 
+// AttachBufferContext is like AttachBuffer, but uses ctx to cancel the request.
+func (v *Nvim) AttachBufferContext(ctx context.Context, buffer Buffer, sendBuffer bool, opts map[string]interface{}) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_attach", &result, buffer, sendBuffer, opts)
+	return result, err
+}
+
 // AttachBuffer activate updates from this buffer to the current channel.
 //
 // If sendBuffer is true, initial notification should contain the whole buffer.
@@ -160,6 +182,13 @@ func (v *Nvim) DetachBuffer(buffer Buffer) (bool, error) {
 	return result, err
 }
 
+// DetachBufferContext is like DetachBuffer, but uses ctx to cancel the request.
+func (v *Nvim) DetachBufferContext(ctx context.Context, buffer Buffer) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_detach", &result, buffer)
+	return result, err
+}
+
 // DetachBuffer deactivate updates from this buffer to the current channel.
 //
 // returns whether the updates couldn't be disabled because the buffer isn't loaded.
@@ -182,6 +211,11 @@ func (v *Nvim) SetBufferLines(buffer Buffer, start int, end int, strict bool, re
 	return v.call("nvim_buf_set_lines", nil, buffer, start, end, strict, replacement)
 }
 
+// SetBufferLinesContext is like SetBufferLines, but uses ctx to cancel the request.
+func (v *Nvim) SetBufferLinesContext(ctx context.Context, buffer Buffer, start int, end int, strict bool, replacement [][]byte) error {
+	return v.callContext(ctx, "nvim_buf_set_lines", nil, buffer, start, end, strict, replacement)
+}
+
 // SetBufferLines replaces a line range on a buffer.
 //
 // Indexing is zero-based, end-exclusive. Negative indices are interpreted as
@@ -212,6 +246,13 @@ func (v *Nvim) BufferOffset(buffer Buffer, index int) (int, error) {
 	return result, err
 }
 
+// BufferOffsetContext is like BufferOffset, but uses ctx to cancel the request.
+func (v *Nvim) BufferOffsetContext(ctx context.Context, buffer Buffer, index int) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_get_offset", &result, buffer, index)
+	return result, err
+}
+
 // BufferOffset returns the byte offset for a line.
 //
 // Line 1 (index=0) has offset 0. UTF-8 bytes are counted. EOL is one byte.
@@ -230,6 +271,11 @@ func (v *Nvim) BufferVar(buffer Buffer, name string, result interface{}) error {
 	return v.call("nvim_buf_get_var", result, buffer, name)
 }
 
+// BufferVarContext is like BufferVar, but uses ctx to cancel the request.
+func (v *Nvim) BufferVarContext(ctx context.Context, buffer Buffer, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_buf_get_var", result, buffer, name)
+}
+
 // BufferVar gets a buffer-scoped (b:) variable.
 func (b *Batch) BufferVar(buffer Buffer, name string, result interface{}) {
 	b.call("nvim_buf_get_var", result, buffer, name)
@@ -242,6 +288,13 @@ func (v *Nvim) BufferChangedTick(buffer Buffer) (int, error) {
 	return result, err
 }
 
+// BufferChangedTickContext is like BufferChangedTick, but uses ctx to cancel the request.
+func (v *Nvim) BufferChangedTickContext(ctx context.Context, buffer Buffer) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_get_changedtick", &result, buffer)
+	return result, err
+}
+
 // BufferChangedTick gets a changed tick of a buffer.
 func (b *Batch) BufferChangedTick(buffer Buffer, result *int) {
 	b.call("nvim_buf_get_changedtick", result, buffer)
@@ -254,6 +307,13 @@ func (v *Nvim) BufferKeyMap(buffer Buffer, mode string) ([]*Mapping, error) {
 	return result, err
 }
 
+// BufferKeyMapContext is like BufferKeyMap, but uses ctx to cancel the request.
+func (v *Nvim) BufferKeyMapContext(ctx context.Context, buffer Buffer, mode string) ([]*Mapping, error) {
+	var result []*Mapping
+	err := v.callContext(ctx, "nvim_buf_get_keymap", &result, buffer, mode)
+	return result, err
+}
+
 // BufferKeymap gets a list of buffer-local mappings.
 func (b *Batch) BufferKeyMap(buffer Buffer, mode string, result *[]*Mapping) {
 	b.call("nvim_buf_get_keymap", result, buffer, mode)
@@ -267,6 +327,11 @@ func (v *Nvim) SetBufferKeyMap(buffer Buffer, mode string, lhs string, rhs strin
 	return v.call("nvim_buf_set_keymap", nil, buffer, mode, lhs, rhs, opts)
 }
 
+// SetBufferKeyMapContext is like SetBufferKeyMap, but uses ctx to cancel the request.
+func (v *Nvim) SetBufferKeyMapContext(ctx context.Context, buffer Buffer, mode string, lhs string, rhs string, opts map[string]bool) error {
+	return v.callContext(ctx, "nvim_buf_set_keymap", nil, buffer, mode, lhs, rhs, opts)
+}
+
 // SetBufferKeyMap sets a buffer-local mapping for the given mode.
 //
 // see
@@ -283,6 +348,11 @@ func (v *Nvim) DeleteBufferKeyMap(buffer Buffer, mode string, lhs string) error
 	return v.call("nvim_buf_del_keymap", nil, buffer, mode, lhs)
 }
 
+// DeleteBufferKeyMapContext is like DeleteBufferKeyMap, but uses ctx to cancel the request.
+func (v *Nvim) DeleteBufferKeyMapContext(ctx context.Context, buffer Buffer, mode string, lhs string) error {
+	return v.callContext(ctx, "nvim_buf_del_keymap", nil, buffer, mode, lhs)
+}
+
 // DeleteBufferKeyMap unmaps a buffer-local mapping for the given mode.
 //
 // see
@@ -300,6 +370,13 @@ func (v *Nvim) BufferCommands(buffer Buffer, opts map[string]interface{}) (map[s
 	return result, err
 }
 
+// BufferCommandsContext is like BufferCommands, but uses ctx to cancel the request.
+func (v *Nvim) BufferCommandsContext(ctx context.Context, buffer Buffer, opts map[string]interface{}) (map[string]*Command, error) {
+	var result map[string]*Command
+	err := v.callContext(ctx, "nvim_buf_get_commands", &result, buffer, opts)
+	return result, err
+}
+
 // BufferCommands gets a map of buffer-local user-commands.
 //
 // opts is optional parameters. Currently not used.
@@ -312,6 +389,11 @@ func (v *Nvim) SetBufferVar(buffer Buffer, name string, value interface{}) error
 	return v.call("nvim_buf_set_var", nil, buffer, name, value)
 }
 
+// SetBufferVarContext is like SetBufferVar, but uses ctx to cancel the request.
+func (v *Nvim) SetBufferVarContext(ctx context.Context, buffer Buffer, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_buf_set_var", nil, buffer, name, value)
+}
+
 // SetBufferVar sets a buffer-scoped (b:) variable.
 func (b *Batch) SetBufferVar(buffer Buffer, name string, value interface{}) {
 	b.call("nvim_buf_set_var", nil, buffer, name, value)
@@ -322,6 +404,11 @@ func (v *Nvim) DeleteBufferVar(buffer Buffer, name string) error {
 	return v.call("nvim_buf_del_var", nil, buffer, name)
 }
 
+// DeleteBufferVarContext is like DeleteBufferVar, but uses ctx to cancel the request.
+func (v *Nvim) DeleteBufferVarContext(ctx context.Context, buffer Buffer, name string) error {
+	return v.callContext(ctx, "nvim_buf_del_var", nil, buffer, name)
+}
+
 // DeleteBufferVar removes a buffer-scoped (b:) variable.
 func (b *Batch) DeleteBufferVar(buffer Buffer, name string) {
 	b.call("nvim_buf_del_var", nil, buffer, name)
@@ -332,6 +419,11 @@ func (v *Nvim) BufferOption(buffer Buffer, name string, result interface{}) erro
 	return v.call("nvim_buf_get_option", result, buffer, name)
 }
 
+// BufferOptionContext is like BufferOption, but uses ctx to cancel the request.
+func (v *Nvim) BufferOptionContext(ctx context.Context, buffer Buffer, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_buf_get_option", result, buffer, name)
+}
+
 // BufferOption gets a buffer option value.
 func (b *Batch) BufferOption(buffer Buffer, name string, result interface{}) {
 	b.call("nvim_buf_get_option", result, buffer, name)
@@ -343,6 +435,11 @@ func (v *Nvim) SetBufferOption(buffer Buffer, name string, value interface{}) er
 	return v.call("nvim_buf_set_option", nil, buffer, name, value)
 }
 
+// SetBufferOptionContext is like SetBufferOption, but uses ctx to cancel the request.
+func (v *Nvim) SetBufferOptionContext(ctx context.Context, buffer Buffer, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_buf_set_option", nil, buffer, name, value)
+}
+
 // SetBufferOption sets a buffer option value. The value nil deletes the option
 // in the case where there's a global fallback.
 func (b *Batch) SetBufferOption(buffer Buffer, name string, value interface{}) {
@@ -358,6 +455,13 @@ func (v *Nvim) BufferNumber(buffer Buffer) (int, error) {
 	return result, err
 }
 
+// BufferNumberContext is like BufferNumber, but uses ctx to cancel the request.
+func (v *Nvim) BufferNumberContext(ctx context.Context, buffer Buffer) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_get_number", &result, buffer)
+	return result, err
+}
+
 // BufferNumber gets a buffer's number.
 //
 // Deprecated: Use int(buffer) to get the buffer's number as an integer.
@@ -372,6 +476,13 @@ func (v *Nvim) BufferName(buffer Buffer) (string, error) {
 	return result, err
 }
 
+// BufferNameContext is like BufferName, but uses ctx to cancel the request.
+func (v *Nvim) BufferNameContext(ctx context.Context, buffer Buffer) (string, error) {
+	var result string
+	err := v.callContext(ctx, "nvim_buf_get_name", &result, buffer)
+	return result, err
+}
+
 // BufferName gets the full file name of a buffer.
 func (b *Batch) BufferName(buffer Buffer, result *string) {
 	b.call("nvim_buf_get_name", result, buffer)
@@ -383,6 +494,11 @@ func (v *Nvim) SetBufferName(buffer Buffer, name string) error {
 	return v.call("nvim_buf_set_name", nil, buffer, name)
 }
 
+// SetBufferNameContext is like SetBufferName, but uses ctx to cancel the request.
+func (v *Nvim) SetBufferNameContext(ctx context.Context, buffer Buffer, name string) error {
+	return v.callContext(ctx, "nvim_buf_set_name", nil, buffer, name)
+}
+
 // SetBufferName sets the full file name of a buffer.
 // BufFilePre/BufFilePost are triggered.
 func (b *Batch) SetBufferName(buffer Buffer, name string) {
@@ -397,6 +513,13 @@ func (v *Nvim) IsBufferLoaded(buffer Buffer) (bool, error) {
 	return result, err
 }
 
+// IsBufferLoadedContext is like IsBufferLoaded, but uses ctx to cancel the request.
+func (v *Nvim) IsBufferLoadedContext(ctx context.Context, buffer Buffer) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_is_loaded", &result, buffer)
+	return result, err
+}
+
 // IsBufferLoaded Checks if a buffer is valid and loaded.
 // See api-buffer for more info about unloaded buffers.
 func (b *Batch) IsBufferLoaded(buffer Buffer, result *bool) {
@@ -410,6 +533,13 @@ func (v *Nvim) IsBufferValid(buffer Buffer) (bool, error) {
 	return result, err
 }
 
+// IsBufferValidContext is like IsBufferValid, but uses ctx to cancel the request.
+func (v *Nvim) IsBufferValidContext(ctx context.Context, buffer Buffer) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_is_valid", &result, buffer)
+	return result, err
+}
+
 // IsBufferValid returns true if the buffer is valid.
 func (b *Batch) IsBufferValid(buffer Buffer, result *bool) {
 	b.call("nvim_buf_is_valid", result, buffer)
@@ -422,6 +552,13 @@ func (v *Nvim) BufferMark(buffer Buffer, name string) ([2]int, error) {
 	return result, err
 }
 
+// BufferMarkContext is like BufferMark, but uses ctx to cancel the request.
+func (v *Nvim) BufferMarkContext(ctx context.Context, buffer Buffer, name string) ([2]int, error) {
+	var result [2]int
+	err := v.callContext(ctx, "nvim_buf_get_mark", &result, buffer, name)
+	return result, err
+}
+
 // BufferMark returns the (row,col) of the named mark.
 func (b *Batch) BufferMark(buffer Buffer, name string, result *[2]int) {
 	b.call("nvim_buf_get_mark", result, buffer, name)
@@ -434,6 +571,13 @@ func (v *Nvim) BufferExtmarkByID(buffer Buffer, nsID int, id int) ([]int, error)
 	return result, err
 }
 
+// BufferExtmarkByIDContext is like BufferExtmarkByID, but uses ctx to cancel the request.
+func (v *Nvim) BufferExtmarkByIDContext(ctx context.Context, buffer Buffer, nsID int, id int) ([]int, error) {
+	var result []int
+	err := v.callContext(ctx, "nvim_buf_get_extmark_by_id", &result, buffer, nsID, id)
+	return result, err
+}
+
 // BufferExtmarkByID returns position for a given extmark id.
 func (b *Batch) BufferExtmarkByID(buffer Buffer, nsID int, id int, result *[]int) {
 	b.call("nvim_buf_get_extmark_by_id", result, buffer, nsID, id)
@@ -460,6 +604,13 @@ func (v *Nvim) BufferExtmarks(buffer Buffer, nsID int, start interface{}, end in
 	return result, err
 }
 
+// BufferExtmarksContext is like BufferExtmarks, but uses ctx to cancel the request.
+func (v *Nvim) BufferExtmarksContext(ctx context.Context, buffer Buffer, nsID int, start interface{}, end interface{}, opt map[string]interface{}) ([]interface{}, error) {
+	var result []interface{}
+	err := v.callContext(ctx, "nvim_buf_get_extmarks", &result, buffer, nsID, start, end, opt)
+	return result, err
+}
+
 // BufferExtmarks gets extmarks in "traversal order" from a charwise region defined by
 // buffer positions (inclusive, 0-indexed).
 //
@@ -495,6 +646,13 @@ func (v *Nvim) SetBufferExtmark(buffer Buffer, nsID int, extmarkID int, line int
 	return result, err
 }
 
+// SetBufferExtmarkContext is like SetBufferExtmark, but uses ctx to cancel the request.
+func (v *Nvim) SetBufferExtmarkContext(ctx context.Context, buffer Buffer, nsID int, extmarkID int, line int, col int, opts map[string]interface{}) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_set_extmark", &result, buffer, nsID, extmarkID, line, col, opts)
+	return result, err
+}
+
 // SetBufferExtmark creates or updates an extmark.
 //
 // To create a new extmark, pass id=0. The extmark id will be returned.
@@ -516,6 +674,13 @@ func (v *Nvim) DeleteBufferExtmark(buffer Buffer, nsID int, extmarkID int) (bool
 	return result, err
 }
 
+// DeleteBufferExtmarkContext is like DeleteBufferExtmark, but uses ctx to cancel the request.
+func (v *Nvim) DeleteBufferExtmarkContext(ctx context.Context, buffer Buffer, nsID int, extmarkID int) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_del_extmark", &result, buffer, nsID, extmarkID)
+	return result, err
+}
+
 // DeleteBufferExtmark removes an extmark.
 func (b *Batch) DeleteBufferExtmark(buffer Buffer, nsID int, extmarkID int, result *bool) {
 	b.call("nvim_buf_del_extmark", result, buffer, nsID, extmarkID)
@@ -550,6 +715,13 @@ func (v *Nvim) AddBufferHighlight(buffer Buffer, srcID int, hlGroup string, line
 	return result, err
 }
 
+// AddBufferHighlightContext is like AddBufferHighlight, but uses ctx to cancel the request.
+func (v *Nvim) AddBufferHighlightContext(ctx context.Context, buffer Buffer, srcID int, hlGroup string, line int, startCol int, endCol int) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_add_highlight", &result, buffer, srcID, hlGroup, line, startCol, endCol)
+	return result, err
+}
+
 // AddBufferHighlight adds a highlight to buffer and returns the source id of
 // the highlight.
 //
@@ -585,6 +757,11 @@ func (v *Nvim) ClearBufferNamespace(buffer Buffer, nsID int, lineStart int, line
 	return v.call("nvim_buf_clear_namespace", nil, buffer, nsID, lineStart, lineEnd)
 }
 
+// ClearBufferNamespaceContext is like ClearBufferNamespace, but uses ctx to cancel the request.
+func (v *Nvim) ClearBufferNamespaceContext(ctx context.Context, buffer Buffer, nsID int, lineStart int, lineEnd int) error {
+	return v.callContext(ctx, "nvim_buf_clear_namespace", nil, buffer, nsID, lineStart, lineEnd)
+}
+
 // ClearBufferNamespace clears namespaced objects, highlights and virtual text, from a line range.
 //
 // To clear the namespace in the entire buffer, pass in 0 and -1 to
@@ -607,6 +784,11 @@ func (v *Nvim) ClearBufferHighlight(buffer Buffer, srcID int, startLine int, end
 	return v.call("nvim_buf_clear_highlight", nil, buffer, srcID, startLine, endLine)
 }
 
+// ClearBufferHighlightContext is like ClearBufferHighlight, but uses ctx to cancel the request.
+func (v *Nvim) ClearBufferHighlightContext(ctx context.Context, buffer Buffer, srcID int, startLine int, endLine int) error {
+	return v.callContext(ctx, "nvim_buf_clear_highlight", nil, buffer, srcID, startLine, endLine)
+}
+
 // ClearBufferHighlight clears highlights from a given source group and a range
 // of lines.
 //
@@ -646,6 +828,13 @@ func (v *Nvim) SetBufferVirtualText(buffer Buffer, nsID int, line int, chunks []
 	return result, err
 }
 
+// SetBufferVirtualTextContext is like SetBufferVirtualText, but uses ctx to cancel the request.
+func (v *Nvim) SetBufferVirtualTextContext(ctx context.Context, buffer Buffer, nsID int, line int, chunks []VirtualTextChunk, opts map[string]interface{}) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_set_virtual_text", &result, buffer, nsID, line, chunks, opts)
+	return result, err
+}
+
 // SetBufferVirtualText sets the virtual text (annotation) for a buffer line.
 //
 // By default (and currently the only option) the text will be placed after
@@ -685,6 +874,13 @@ func (v *Nvim) BufferVirtualText(buffer Buffer, lnum int) ([]VirtualTextChunk, e
 	return result, err
 }
 
+// BufferVirtualTextContext is like BufferVirtualText, but uses ctx to cancel the request.
+func (v *Nvim) BufferVirtualTextContext(ctx context.Context, buffer Buffer, lnum int) ([]VirtualTextChunk, error) {
+	var result []VirtualTextChunk
+	err := v.callContext(ctx, "nvim_buf_get_virtual_text", &result, buffer, lnum)
+	return result, err
+}
+
 // BufferVirtualText gets the virtual text (annotation) for a buffer line.
 //
 // The virtual text is returned as list of lists, whereas the inner lists have
@@ -706,6 +902,13 @@ func (v *Nvim) TabpageWindows(tabpage Tabpage) ([]Window, error) {
 	return result, err
 }
 
+// TabpageWindowsContext is like TabpageWindows, but uses ctx to cancel the request.
+func (v *Nvim) TabpageWindowsContext(ctx context.Context, tabpage Tabpage) ([]Window, error) {
+	var result []Window
+	err := v.callContext(ctx, "nvim_tabpage_list_wins", &result, tabpage)
+	return result, err
+}
+
 // TabpageWindows returns the windows in a tabpage.
 func (b *Batch) TabpageWindows(tabpage Tabpage, result *[]Window) {
 	b.call("nvim_tabpage_list_wins", result, tabpage)
@@ -716,6 +919,11 @@ func (v *Nvim) TabpageVar(tabpage Tabpage, name string, result interface{}) erro
 	return v.call("nvim_tabpage_get_var", result, tabpage, name)
 }
 
+// TabpageVarContext is like TabpageVar, but uses ctx to cancel the request.
+func (v *Nvim) TabpageVarContext(ctx context.Context, tabpage Tabpage, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_tabpage_get_var", result, tabpage, name)
+}
+
 // TabpageVar gets a tab-scoped (t:) variable.
 func (b *Batch) TabpageVar(tabpage Tabpage, name string, result interface{}) {
 	b.call("nvim_tabpage_get_var", result, tabpage, name)
@@ -726,6 +934,11 @@ func (v *Nvim) SetTabpageVar(tabpage Tabpage, name string, value interface{}) er
 	return v.call("nvim_tabpage_set_var", nil, tabpage, name, value)
 }
 
+// SetTabpageVarContext is like SetTabpageVar, but uses ctx to cancel the request.
+func (v *Nvim) SetTabpageVarContext(ctx context.Context, tabpage Tabpage, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_tabpage_set_var", nil, tabpage, name, value)
+}
+
 // SetTabpageVar sets a tab-scoped (t:) variable.
 func (b *Batch) SetTabpageVar(tabpage Tabpage, name string, value interface{}) {
 	b.call("nvim_tabpage_set_var", nil, tabpage, name, value)
@@ -736,6 +949,11 @@ func (v *Nvim) DeleteTabpageVar(tabpage Tabpage, name string) error {
 	return v.call("nvim_tabpage_del_var", nil, tabpage, name)
 }
 
+// DeleteTabpageVarContext is like DeleteTabpageVar, but uses ctx to cancel the request.
+func (v *Nvim) DeleteTabpageVarContext(ctx context.Context, tabpage Tabpage, name string) error {
+	return v.callContext(ctx, "nvim_tabpage_del_var", nil, tabpage, name)
+}
+
 // DeleteTabpageVar removes a tab-scoped (t:) variable.
 func (b *Batch) DeleteTabpageVar(tabpage Tabpage, name string) {
 	b.call("nvim_tabpage_del_var", nil, tabpage, name)
@@ -748,6 +966,13 @@ func (v *Nvim) TabpageWindow(tabpage Tabpage) (Window, error) {
 	return result, err
 }
 
+// TabpageWindowContext is like TabpageWindow, but uses ctx to cancel the request.
+func (v *Nvim) TabpageWindowContext(ctx context.Context, tabpage Tabpage) (Window, error) {
+	var result Window
+	err := v.callContext(ctx, "nvim_tabpage_get_win", &result, tabpage)
+	return result, err
+}
+
 // TabpageWindow gets the current window in a tab page.
 func (b *Batch) TabpageWindow(tabpage Tabpage, result *Window) {
 	b.call("nvim_tabpage_get_win", result, tabpage)
@@ -760,6 +985,13 @@ func (v *Nvim) TabpageNumber(tabpage Tabpage) (int, error) {
 	return result, err
 }
 
+// TabpageNumberContext is like TabpageNumber, but uses ctx to cancel the request.
+func (v *Nvim) TabpageNumberContext(ctx context.Context, tabpage Tabpage) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_tabpage_get_number", &result, tabpage)
+	return result, err
+}
+
 // TabpageNumber gets the tabpage number from the tabpage handle.
 func (b *Batch) TabpageNumber(tabpage Tabpage, result *int) {
 	b.call("nvim_tabpage_get_number", result, tabpage)
@@ -772,6 +1004,13 @@ func (v *Nvim) IsTabpageValid(tabpage Tabpage) (bool, error) {
 	return result, err
 }
 
+// IsTabpageValidContext is like IsTabpageValid, but uses ctx to cancel the request.
+func (v *Nvim) IsTabpageValidContext(ctx context.Context, tabpage Tabpage) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_tabpage_is_valid", &result, tabpage)
+	return result, err
+}
+
 // IsTabpageValid checks if a tab page is valid.
 func (b *Batch) IsTabpageValid(tabpage Tabpage, result *bool) {
 	b.call("nvim_tabpage_is_valid", result, tabpage)
@@ -794,6 +1033,11 @@ func (v *Nvim) AttachUI(width int, height int, options map[string]interface{}) e
 	return v.call("nvim_ui_attach", nil, width, height, options)
 }
 
+// AttachUIContext is like AttachUI, but uses ctx to cancel the request.
+func (v *Nvim) AttachUIContext(ctx context.Context, width int, height int, options map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_ui_attach", nil, width, height, options)
+}
+
 // AttachUI registers the client as a remote UI. After this method is called,
 // the client will receive redraw notifications.
 //
@@ -816,6 +1060,11 @@ func (v *Nvim) DetachUI() error {
 	return v.call("nvim_ui_detach", nil)
 }
 
+// DetachUIContext is like DetachUI, but uses ctx to cancel the request.
+func (v *Nvim) DetachUIContext(ctx context.Context) error {
+	return v.callContext(ctx, "nvim_ui_detach", nil)
+}
+
 // DetachUI unregisters the client as a remote UI.
 func (b *Batch) DetachUI() {
 	b.call("nvim_ui_detach", nil)
@@ -827,6 +1076,11 @@ func (v *Nvim) TryResizeUI(width int, height int) error {
 	return v.call("nvim_ui_try_resize", nil, width, height)
 }
 
+// TryResizeUIContext is like TryResizeUI, but uses ctx to cancel the request.
+func (v *Nvim) TryResizeUIContext(ctx context.Context, width int, height int) error {
+	return v.callContext(ctx, "nvim_ui_try_resize", nil, width, height)
+}
+
 // TryResizeUI notifies Nvim that the client window has resized. If possible,
 // Nvim will send a redraw request to resize.
 func (b *Batch) TryResizeUI(width int, height int) {
@@ -838,6 +1092,11 @@ func (v *Nvim) SetUIOption(name string, value interface{}) error {
 	return v.call("nvim_ui_set_option", nil, name, value)
 }
 
+// SetUIOptionContext is like SetUIOption, but uses ctx to cancel the request.
+func (v *Nvim) SetUIOptionContext(ctx context.Context, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_ui_set_option", nil, name, value)
+}
+
 // SetUIOption sets a UI option.
 func (b *Batch) SetUIOption(name string, value interface{}) {
 	b.call("nvim_ui_set_option", nil, name, value)
@@ -851,6 +1110,11 @@ func (v *Nvim) TryResizeUIGrid(grid int, width int, height int) error {
 	return v.call("nvim_ui_try_resize_grid", nil, grid, width, height)
 }
 
+// TryResizeUIGridContext is like TryResizeUIGrid, but uses ctx to cancel the request.
+func (v *Nvim) TryResizeUIGridContext(ctx context.Context, grid int, width int, height int) error {
+	return v.callContext(ctx, "nvim_ui_try_resize_grid", nil, grid, width, height)
+}
+
 // TryResizeUIGrid tell Nvim to resize a grid. Triggers a grid_resize event with the requested
 // grid size or the maximum size if it exceeds size limits.
 //
@@ -867,6 +1131,11 @@ func (v *Nvim) SetPumHeight(height int) error {
 	return v.call("nvim_ui_pum_set_height", nil, height)
 }
 
+// SetPumHeightContext is like SetPumHeight, but uses ctx to cancel the request.
+func (v *Nvim) SetPumHeightContext(ctx context.Context, height int) error {
+	return v.callContext(ctx, "nvim_ui_pum_set_height", nil, height)
+}
+
 // SetPumHeight tells Nvim the number of elements displaying in the popumenu, to decide
 // <PageUp> and <PageDown> movement.
 //
@@ -880,6 +1149,11 @@ func (v *Nvim) Command(cmd string) error {
 	return v.call("nvim_command", nil, cmd)
 }
 
+// CommandContext is like Command, but uses ctx to cancel the request.
+func (v *Nvim) CommandContext(ctx context.Context, cmd string) error {
+	return v.callContext(ctx, "nvim_command", nil, cmd)
+}
+
 // Command executes a single ex command.
 func (b *Batch) Command(cmd string) {
 	b.call("nvim_command", nil, cmd)
@@ -892,6 +1166,13 @@ func (v *Nvim) HLByID(id int, rgb bool) (*HLAttrs, error) {
 	return result, err
 }
 
+// HLByIDContext is like HLByID, but uses ctx to cancel the request.
+func (v *Nvim) HLByIDContext(ctx context.Context, id int, rgb bool) (*HLAttrs, error) {
+	var result *HLAttrs
+	err := v.callContext(ctx, "nvim_get_hl_by_id", &result, id, rgb)
+	return result, err
+}
+
 // HLByID gets a highlight definition by id.
 func (b *Batch) HLByID(id int, rgb bool, result **HLAttrs) {
 	b.call("nvim_get_hl_by_id", result, id, rgb)
@@ -904,6 +1185,13 @@ func (v *Nvim) HLByName(name string, rgb bool) (*HLAttrs, error) {
 	return result, err
 }
 
+// HLByNameContext is like HLByName, but uses ctx to cancel the request.
+func (v *Nvim) HLByNameContext(ctx context.Context, name string, rgb bool) (*HLAttrs, error) {
+	var result *HLAttrs
+	err := v.callContext(ctx, "nvim_get_hl_by_name", &result, name, rgb)
+	return result, err
+}
+
 // HLByName gets a highlight definition by name.
 func (b *Batch) HLByName(name string, rgb bool, result **HLAttrs) {
 	b.call("nvim_get_hl_by_name", result, name, rgb)
@@ -920,6 +1208,11 @@ func (v *Nvim) FeedKeys(keys string, mode string, escapeCSI bool) error {
 	return v.call("nvim_feedkeys", nil, keys, mode, escapeCSI)
 }
 
+// FeedKeysContext is like FeedKeys, but uses ctx to cancel the request.
+func (v *Nvim) FeedKeysContext(ctx context.Context, keys string, mode string, escapeCSI bool) error {
+	return v.callContext(ctx, "nvim_feedkeys", nil, keys, mode, escapeCSI)
+}
+
 // FeedKeys Pushes keys to the Nvim user input buffer. Options can be a string
 // with the following character flags:
 //
@@ -942,6 +1235,13 @@ func (v *Nvim) Input(keys string) (int, error) {
 	return result, err
 }
 
+// InputContext is like Input, but uses ctx to cancel the request.
+func (v *Nvim) InputContext(ctx context.Context, keys string) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_input", &result, keys)
+	return result, err
+}
+
 // Input pushes bytes to the Nvim low level input buffer.
 //
 // Unlike FeedKeys, this uses the lowest level input buffer and the call is not
@@ -959,6 +1259,11 @@ func (v *Nvim) InputMouse(button string, action string, modifier string, grid in
 	return v.call("nvim_input_mouse", nil, button, action, modifier, grid, row, col)
 }
 
+// InputMouseContext is like InputMouse, but uses ctx to cancel the request.
+func (v *Nvim) InputMouseContext(ctx context.Context, button string, action string, modifier string, grid int, row int, col int) error {
+	return v.callContext(ctx, "nvim_input_mouse", nil, button, action, modifier, grid, row, col)
+}
+
 // InputMouse send mouse event from GUI.
 //
 // The call is non-blocking. It doesn't wait on any resulting action, but
@@ -982,6 +1287,13 @@ func (v *Nvim) ReplaceTermcodes(str string, fromPart bool, doLT bool, special bo
 	return result, err
 }
 
+// ReplaceTermcodesContext is like ReplaceTermcodes, but uses ctx to cancel the request.
+func (v *Nvim) ReplaceTermcodesContext(ctx context.Context, str string, fromPart bool, doLT bool, special bool) (string, error) {
+	var result string
+	err := v.callContext(ctx, "nvim_replace_termcodes", &result, str, fromPart, doLT, special)
+	return result, err
+}
+
 // ReplaceTermcodes replaces any terminal code strings by byte sequences. The
 // returned sequences are Nvim's internal representation of keys, for example:
 //
@@ -1002,6 +1314,13 @@ func (v *Nvim) CommandOutput(cmd string) (string, error) {
 	return result, err
 }
 
+// CommandOutputContext is like CommandOutput, but uses ctx to cancel the request.
+func (v *Nvim) CommandOutputContext(ctx context.Context, cmd string) (string, error) {
+	var result string
+	err := v.callContext(ctx, "nvim_command_output", &result, cmd)
+	return result, err
+}
+
 // CommandOutput executes a single ex command and returns the output.
 func (b *Batch) CommandOutput(cmd string, result *string) {
 	b.call("nvim_command_output", result, cmd)
@@ -1015,6 +1334,11 @@ func (v *Nvim) Eval(expr string, result interface{}) error {
 	return v.call("nvim_eval", result, expr)
 }
 
+// EvalContext is like Eval, but uses ctx to cancel the request.
+func (v *Nvim) EvalContext(ctx context.Context, expr string, result interface{}) error {
+	return v.callContext(ctx, "nvim_eval", result, expr)
+}
+
 // Eval evaluates the expression expr using the Vim internal expression
 // evaluator.
 //
@@ -1031,6 +1355,13 @@ func (v *Nvim) StringWidth(s string) (int, error) {
 	return result, err
 }
 
+// StringWidthContext is like StringWidth, but uses ctx to cancel the request.
+func (v *Nvim) StringWidthContext(ctx context.Context, s string) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_strwidth", &result, s)
+	return result, err
+}
+
 // StringWidth returns the number of display cells the string occupies. Tab is
 // counted as one cell.
 func (b *Batch) StringWidth(s string, result *int) {
@@ -1044,6 +1375,13 @@ func (v *Nvim) RuntimePaths() ([]string, error) {
 	return result, err
 }
 
+// RuntimePathsContext is like RuntimePaths, but uses ctx to cancel the request.
+func (v *Nvim) RuntimePathsContext(ctx context.Context) ([]string, error) {
+	var result []string
+	err := v.callContext(ctx, "nvim_list_runtime_paths", &result)
+	return result, err
+}
+
 // RuntimePaths returns a list of paths contained in the runtimepath option.
 func (b *Batch) RuntimePaths(result *[]string) {
 	b.call("nvim_list_runtime_paths", result)
@@ -1054,6 +1392,11 @@ func (v *Nvim) SetCurrentDirectory(dir string) error {
 	return v.call("nvim_set_current_dir", nil, dir)
 }
 
+// SetCurrentDirectoryContext is like SetCurrentDirectory, but uses ctx to cancel the request.
+func (v *Nvim) SetCurrentDirectoryContext(ctx context.Context, dir string) error {
+	return v.callContext(ctx, "nvim_set_current_dir", nil, dir)
+}
+
 // SetCurrentDirectory changes the Vim working directory.
 func (b *Batch) SetCurrentDirectory(dir string) {
 	b.call("nvim_set_current_dir", nil, dir)
@@ -1066,6 +1409,13 @@ func (v *Nvim) CurrentLine() ([]byte, error) {
 	return result, err
 }
 
+// CurrentLineContext is like CurrentLine, but uses ctx to cancel the request.
+func (v *Nvim) CurrentLineContext(ctx context.Context) ([]byte, error) {
+	var result []byte
+	err := v.callContext(ctx, "nvim_get_current_line", &result)
+	return result, err
+}
+
 // CurrentLine gets the current line in the current buffer.
 func (b *Batch) CurrentLine(result *[]byte) {
 	b.call("nvim_get_current_line", result)
@@ -1076,6 +1426,11 @@ func (v *Nvim) SetCurrentLine(line []byte) error {
 	return v.call("nvim_set_current_line", nil, line)
 }
 
+// SetCurrentLineContext is like SetCurrentLine, but uses ctx to cancel the request.
+func (v *Nvim) SetCurrentLineContext(ctx context.Context, line []byte) error {
+	return v.callContext(ctx, "nvim_set_current_line", nil, line)
+}
+
 // SetCurrentLine sets the current line in the current buffer.
 func (b *Batch) SetCurrentLine(line []byte) {
 	b.call("nvim_set_current_line", nil, line)
@@ -1086,6 +1441,11 @@ func (v *Nvim) DeleteCurrentLine() error {
 	return v.call("nvim_del_current_line", nil)
 }
 
+// DeleteCurrentLineContext is like DeleteCurrentLine, but uses ctx to cancel the request.
+func (v *Nvim) DeleteCurrentLineContext(ctx context.Context) error {
+	return v.callContext(ctx, "nvim_del_current_line", nil)
+}
+
 // DeleteCurrentLine deletes the current line in the current buffer.
 func (b *Batch) DeleteCurrentLine() {
 	b.call("nvim_del_current_line", nil)
@@ -1096,6 +1456,11 @@ func (v *Nvim) Var(name string, result interface{}) error {
 	return v.call("nvim_get_var", result, name)
 }
 
+// VarContext is like Var, but uses ctx to cancel the request.
+func (v *Nvim) VarContext(ctx context.Context, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_get_var", result, name)
+}
+
 // Var gets a global (g:) variable.
 func (b *Batch) Var(name string, result interface{}) {
 	b.call("nvim_get_var", result, name)
@@ -1106,6 +1471,11 @@ func (v *Nvim) SetVar(name string, value interface{}) error {
 	return v.call("nvim_set_var", nil, name, value)
 }
 
+// SetVarContext is like SetVar, but uses ctx to cancel the request.
+func (v *Nvim) SetVarContext(ctx context.Context, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_set_var", nil, name, value)
+}
+
 // SetVar sets a global (g:) variable.
 func (b *Batch) SetVar(name string, value interface{}) {
 	b.call("nvim_set_var", nil, name, value)
@@ -1116,6 +1486,11 @@ func (v *Nvim) DeleteVar(name string) error {
 	return v.call("nvim_del_var", nil, name)
 }
 
+// DeleteVarContext is like DeleteVar, but uses ctx to cancel the request.
+func (v *Nvim) DeleteVarContext(ctx context.Context, name string) error {
+	return v.callContext(ctx, "nvim_del_var", nil, name)
+}
+
 // DeleteVar removes a global (g:) variable.
 func (b *Batch) DeleteVar(name string) {
 	b.call("nvim_del_var", nil, name)
@@ -1126,6 +1501,11 @@ func (v *Nvim) VVar(name string, result interface{}) error {
 	return v.call("nvim_get_vvar", result, name)
 }
 
+// VVarContext is like VVar, but uses ctx to cancel the request.
+func (v *Nvim) VVarContext(ctx context.Context, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_get_vvar", result, name)
+}
+
 // VVar gets a vim (v:) variable.
 func (b *Batch) VVar(name string, result interface{}) {
 	b.call("nvim_get_vvar", result, name)
@@ -1136,6 +1516,11 @@ func (v *Nvim) SetVVar(name string, value interface{}) error {
 	return v.call("nvim_set_vvar", nil, name, value)
 }
 
+// SetVVarContext is like SetVVar, but uses ctx to cancel the request.
+func (v *Nvim) SetVVarContext(ctx context.Context, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_set_vvar", nil, name, value)
+}
+
 // SetVVar sets a v: variable, if it is not readonly.
 func (b *Batch) SetVVar(name string, value interface{}) {
 	b.call("nvim_set_vvar", nil, name, value)
@@ -1146,6 +1531,11 @@ func (v *Nvim) Option(name string, result interface{}) error {
 	return v.call("nvim_get_option", result, name)
 }
 
+// OptionContext is like Option, but uses ctx to cancel the request.
+func (v *Nvim) OptionContext(ctx context.Context, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_get_option", result, name)
+}
+
 // Option gets an option.
 func (b *Batch) Option(name string, result interface{}) {
 	b.call("nvim_get_option", result, name)
@@ -1156,6 +1546,11 @@ func (v *Nvim) SetOption(name string, value interface{}) error {
 	return v.call("nvim_set_option", nil, name, value)
 }
 
+// SetOptionContext is like SetOption, but uses ctx to cancel the request.
+func (v *Nvim) SetOptionContext(ctx context.Context, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_set_option", nil, name, value)
+}
+
 // SetOption sets an option.
 func (b *Batch) SetOption(name string, value interface{}) {
 	b.call("nvim_set_option", nil, name, value)
@@ -1167,6 +1562,11 @@ func (v *Nvim) WriteOut(str string) error {
 	return v.call("nvim_out_write", nil, str)
 }
 
+// WriteOutContext is like WriteOut, but uses ctx to cancel the request.
+func (v *Nvim) WriteOutContext(ctx context.Context, str string) error {
+	return v.callContext(ctx, "nvim_out_write", nil, str)
+}
+
 // WriteOut writes a message to vim output buffer. The string is split and
 // flushed after each newline. Incomplete lines are kept for writing later.
 func (b *Batch) WriteOut(str string) {
@@ -1179,6 +1579,11 @@ func (v *Nvim) WriteErr(str string) error {
 	return v.call("nvim_err_write", nil, str)
 }
 
+// WriteErrContext is like WriteErr, but uses ctx to cancel the request.
+func (v *Nvim) WriteErrContext(ctx context.Context, str string) error {
+	return v.callContext(ctx, "nvim_err_write", nil, str)
+}
+
 // WriteErr writes a message to vim error buffer. The string is split and
 // flushed after each newline. Incomplete lines are kept for writing later.
 func (b *Batch) WriteErr(str string) {
@@ -1190,6 +1595,11 @@ func (v *Nvim) WritelnErr(str string) error {
 	return v.call("nvim_err_writeln", nil, str)
 }
 
+// WritelnErrContext is like WritelnErr, but uses ctx to cancel the request.
+func (v *Nvim) WritelnErrContext(ctx context.Context, str string) error {
+	return v.callContext(ctx, "nvim_err_writeln", nil, str)
+}
+
 // WritelnErr writes prints str and a newline as an error message.
 func (b *Batch) WritelnErr(str string) {
 	b.call("nvim_err_writeln", nil, str)
@@ -1202,6 +1612,13 @@ func (v *Nvim) Buffers() ([]Buffer, error) {
 	return result, err
 }
 
+// BuffersContext is like Buffers, but uses ctx to cancel the request.
+func (v *Nvim) BuffersContext(ctx context.Context) ([]Buffer, error) {
+	var result []Buffer
+	err := v.callContext(ctx, "nvim_list_bufs", &result)
+	return result, err
+}
+
 // Buffers returns the current list of buffers.
 func (b *Batch) Buffers(result *[]Buffer) {
 	b.call("nvim_list_bufs", result)
@@ -1214,6 +1631,13 @@ func (v *Nvim) CurrentBuffer() (Buffer, error) {
 	return result, err
 }
 
+// CurrentBufferContext is like CurrentBuffer, but uses ctx to cancel the request.
+func (v *Nvim) CurrentBufferContext(ctx context.Context) (Buffer, error) {
+	var result Buffer
+	err := v.callContext(ctx, "nvim_get_current_buf", &result)
+	return result, err
+}
+
 // CurrentBuffer returns the current buffer.
 func (b *Batch) CurrentBuffer(result *Buffer) {
 	b.call("nvim_get_current_buf", result)
@@ -1224,6 +1648,11 @@ func (v *Nvim) SetCurrentBuffer(buffer Buffer) error {
 	return v.call("nvim_set_current_buf", nil, buffer)
 }
 
+// SetCurrentBufferContext is like SetCurrentBuffer, but uses ctx to cancel the request.
+func (v *Nvim) SetCurrentBufferContext(ctx context.Context, buffer Buffer) error {
+	return v.callContext(ctx, "nvim_set_current_buf", nil, buffer)
+}
+
 // SetCurrentBuffer sets the current buffer.
 func (b *Batch) SetCurrentBuffer(buffer Buffer) {
 	b.call("nvim_set_current_buf", nil, buffer)
@@ -1236,6 +1665,13 @@ func (v *Nvim) Windows() ([]Window, error) {
 	return result, err
 }
 
+// WindowsContext is like Windows, but uses ctx to cancel the request.
+func (v *Nvim) WindowsContext(ctx context.Context) ([]Window, error) {
+	var result []Window
+	err := v.callContext(ctx, "nvim_list_wins", &result)
+	return result, err
+}
+
 // Windows returns the current list of windows.
 func (b *Batch) Windows(result *[]Window) {
 	b.call("nvim_list_wins", result)
@@ -1248,6 +1684,13 @@ func (v *Nvim) CurrentWindow() (Window, error) {
 	return result, err
 }
 
+// CurrentWindowContext is like CurrentWindow, but uses ctx to cancel the request.
+func (v *Nvim) CurrentWindowContext(ctx context.Context) (Window, error) {
+	var result Window
+	err := v.callContext(ctx, "nvim_get_current_win", &result)
+	return result, err
+}
+
 // CurrentWindow returns the current window.
 func (b *Batch) CurrentWindow(result *Window) {
 	b.call("nvim_get_current_win", result)
@@ -1258,6 +1701,11 @@ func (v *Nvim) SetCurrentWindow(window Window) error {
 	return v.call("nvim_set_current_win", nil, window)
 }
 
+// SetCurrentWindowContext is like SetCurrentWindow, but uses ctx to cancel the request.
+func (v *Nvim) SetCurrentWindowContext(ctx context.Context, window Window) error {
+	return v.callContext(ctx, "nvim_set_current_win", nil, window)
+}
+
 // SetCurrentWindow sets the current window.
 func (b *Batch) SetCurrentWindow(window Window) {
 	b.call("nvim_set_current_win", nil, window)
@@ -1270,6 +1718,13 @@ func (v *Nvim) CreateBuffer(listed bool, scratch bool) (Buffer, error) {
 	return result, err
 }
 
+// CreateBufferContext is like CreateBuffer, but uses ctx to cancel the request.
+func (v *Nvim) CreateBufferContext(ctx context.Context, listed bool, scratch bool) (Buffer, error) {
+	var result Buffer
+	err := v.callContext(ctx, "nvim_create_buf", &result, listed, scratch)
+	return result, err
+}
+
 // CreateBuffer creates a new, empty, unnamed buffer.
 func (b *Batch) CreateBuffer(listed bool, scratch bool, result *Buffer) {
 	b.call("nvim_create_buf", result, listed, scratch)
@@ -1306,6 +1761,13 @@ func (v *Nvim) OpenWindow(buffer Buffer, enter bool, config *WindowConfig) (Wind
 	return result, err
 }
 
+// OpenWindowContext is like OpenWindow, but uses ctx to cancel the request.
+func (v *Nvim) OpenWindowContext(ctx context.Context, buffer Buffer, enter bool, config *WindowConfig) (Window, error) {
+	var result Window
+	err := v.callContext(ctx, "nvim_open_win", &result, buffer, enter, config)
+	return result, err
+}
+
 // OpenWindow opens a new window.
 //
 // Currently this is used to open floating and external windows.
@@ -1342,6 +1804,13 @@ func (v *Nvim) Tabpages() ([]Tabpage, error) {
 	return result, err
 }
 
+// TabpagesContext is like Tabpages, but uses ctx to cancel the request.
+func (v *Nvim) TabpagesContext(ctx context.Context) ([]Tabpage, error) {
+	var result []Tabpage
+	err := v.callContext(ctx, "nvim_list_tabpages", &result)
+	return result, err
+}
+
 // Tabpages returns the current list of tabpages.
 func (b *Batch) Tabpages(result *[]Tabpage) {
 	b.call("nvim_list_tabpages", result)
@@ -1354,6 +1823,13 @@ func (v *Nvim) CurrentTabpage() (Tabpage, error) {
 	return result, err
 }
 
+// CurrentTabpageContext is like CurrentTabpage, but uses ctx to cancel the request.
+func (v *Nvim) CurrentTabpageContext(ctx context.Context) (Tabpage, error) {
+	var result Tabpage
+	err := v.callContext(ctx, "nvim_get_current_tabpage", &result)
+	return result, err
+}
+
 // CurrentTabpage returns the current tabpage.
 func (b *Batch) CurrentTabpage(result *Tabpage) {
 	b.call("nvim_get_current_tabpage", result)
@@ -1364,6 +1840,11 @@ func (v *Nvim) SetCurrentTabpage(tabpage Tabpage) error {
 	return v.call("nvim_set_current_tabpage", nil, tabpage)
 }
 
+// SetCurrentTabpageContext is like SetCurrentTabpage, but uses ctx to cancel the request.
+func (v *Nvim) SetCurrentTabpageContext(ctx context.Context, tabpage Tabpage) error {
+	return v.callContext(ctx, "nvim_set_current_tabpage", nil, tabpage)
+}
+
 // SetCurrentTabpage sets the current tabpage.
 func (b *Batch) SetCurrentTabpage(tabpage Tabpage) {
 	b.call("nvim_set_current_tabpage", nil, tabpage)
@@ -1385,6 +1866,13 @@ func (v *Nvim) CreateNamespace(name string) (int, error) {
 	return result, err
 }
 
+// CreateNamespaceContext is like CreateNamespace, but uses ctx to cancel the request.
+func (v *Nvim) CreateNamespaceContext(ctx context.Context, name string) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_create_namespace", &result, name)
+	return result, err
+}
+
 // CreateNamespace creates a new namespace, or gets an existing one.
 //
 // Namespaces are used for buffer highlights and virtual text, see
@@ -1408,6 +1896,13 @@ func (v *Nvim) Namespaces() (map[string]int, error) {
 	return result, err
 }
 
+// NamespacesContext is like Namespaces, but uses ctx to cancel the request.
+func (v *Nvim) NamespacesContext(ctx context.Context) (map[string]int, error) {
+	var result map[string]int
+	err := v.callContext(ctx, "nvim_get_namespaces", &result)
+	return result, err
+}
+
 // Namespaces gets existing named namespaces
 //
 // The return dict that maps from names to namespace ids.
@@ -1442,6 +1937,13 @@ func (v *Nvim) Paste(data string, crlf bool, phase int) (bool, error) {
 	return result, err
 }
 
+// PasteContext is like Paste, but uses ctx to cancel the request.
+func (v *Nvim) PasteContext(ctx context.Context, data string, crlf bool, phase int) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_paste", &result, data, crlf, phase)
+	return result, err
+}
+
 // Paste pastes at cursor, in any mode.
 //
 // Invokes the `vim.paste` handler, which handles each mode appropriately.
@@ -1485,6 +1987,11 @@ func (v *Nvim) Put(lines []string, typ string, after bool, follow bool) error {
 	return v.call("nvim_put", nil, lines, typ, after, follow)
 }
 
+// PutContext is like Put, but uses ctx to cancel the request.
+func (v *Nvim) PutContext(ctx context.Context, lines []string, typ string, after bool, follow bool) error {
+	return v.callContext(ctx, "nvim_put", nil, lines, typ, after, follow)
+}
+
 // Put puts text at cursor, in any mode.
 //
 // Compare :put and p which are always linewise.
@@ -1508,6 +2015,11 @@ func (v *Nvim) Subscribe(event string) error {
 	return v.call("nvim_subscribe", nil, event)
 }
 
+// SubscribeContext is like Subscribe, but uses ctx to cancel the request.
+func (v *Nvim) SubscribeContext(ctx context.Context, event string) error {
+	return v.callContext(ctx, "nvim_subscribe", nil, event)
+}
+
 // Subscribe subscribes to a Nvim event.
 func (b *Batch) Subscribe(event string) {
 	b.call("nvim_subscribe", nil, event)
@@ -1518,6 +2030,11 @@ func (v *Nvim) Unsubscribe(event string) error {
 	return v.call("nvim_unsubscribe", nil, event)
 }
 
+// UnsubscribeContext is like Unsubscribe, but uses ctx to cancel the request.
+func (v *Nvim) UnsubscribeContext(ctx context.Context, event string) error {
+	return v.callContext(ctx, "nvim_unsubscribe", nil, event)
+}
+
 // Unsubscribe unsubscribes to a Nvim event.
 func (b *Batch) Unsubscribe(event string) {
 	b.call("nvim_unsubscribe", nil, event)
@@ -1529,6 +2046,13 @@ func (v *Nvim) ColorByName(name string) (int, error) {
 	return result, err
 }
 
+// ColorByNameContext is like ColorByName, but uses ctx to cancel the request.
+func (v *Nvim) ColorByNameContext(ctx context.Context, name string) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_get_color_by_name", &result, name)
+	return result, err
+}
+
 func (b *Batch) ColorByName(name string, result *int) {
 	b.call("nvim_get_color_by_name", result, name)
 }
@@ -1539,6 +2063,13 @@ func (v *Nvim) ColorMap() (map[string]int, error) {
 	return result, err
 }
 
+// ColorMapContext is like ColorMap, but uses ctx to cancel the request.
+func (v *Nvim) ColorMapContext(ctx context.Context) (map[string]int, error) {
+	var result map[string]int
+	err := v.callContext(ctx, "nvim_get_color_map", &result)
+	return result, err
+}
+
 func (b *Batch) ColorMap(result *map[string]int) {
 	b.call("nvim_get_color_map", result)
 }
@@ -1553,6 +2084,13 @@ func (v *Nvim) Context(opts map[string][]string) (map[string]interface{}, error)
 	return result, err
 }
 
+// ContextContext is like Context, but uses ctx to cancel the request.
+func (v *Nvim) ContextContext(ctx context.Context, opts map[string][]string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_context", &result, opts)
+	return result, err
+}
+
 // Context gets a map of the current editor state.
 //
 // The `opts` is optional parameters.
@@ -1566,6 +2104,11 @@ func (v *Nvim) LoadContext(dict map[string]interface{}, result interface{}) erro
 	return v.call("nvim_load_context", result, dict)
 }
 
+// LoadContextContext is like LoadContext, but uses ctx to cancel the request.
+func (v *Nvim) LoadContextContext(ctx context.Context, dict map[string]interface{}, result interface{}) error {
+	return v.callContext(ctx, "nvim_load_context", result, dict)
+}
+
 // LoadContext sets the current editor state from the given context map.
 func (b *Batch) LoadContext(dict map[string]interface{}, result interface{}) {
 	b.call("nvim_load_context", result, dict)
@@ -1578,6 +2121,13 @@ func (v *Nvim) Mode() (*Mode, error) {
 	return &result, err
 }
 
+// ModeContext is like Mode, but uses ctx to cancel the request.
+func (v *Nvim) ModeContext(ctx context.Context) (*Mode, error) {
+	var result Mode
+	err := v.callContext(ctx, "nvim_get_mode", &result)
+	return &result, err
+}
+
 // Mode gets Nvim's current mode.
 func (b *Batch) Mode(result *Mode) {
 	b.call("nvim_get_mode", result)
@@ -1589,6 +2139,13 @@ func (v *Nvim) KeyMap(mode string) ([]*Mapping, error) {
 	return result, err
 }
 
+// KeyMapContext is like KeyMap, but uses ctx to cancel the request.
+func (v *Nvim) KeyMapContext(ctx context.Context, mode string) ([]*Mapping, error) {
+	var result []*Mapping
+	err := v.callContext(ctx, "nvim_get_keymap", &result, mode)
+	return result, err
+}
+
 func (b *Batch) KeyMap(mode string, result *[]*Mapping) {
 	b.call("nvim_get_keymap", result, mode)
 }
@@ -1617,6 +2174,11 @@ func (v *Nvim) SetKeyMap(mode string, lhs string, rhs string, opts map[string]bo
 	return v.call("nvim_set_keymap", nil, mode, lhs, rhs, opts)
 }
 
+// SetKeyMapContext is like SetKeyMap, but uses ctx to cancel the request.
+func (v *Nvim) SetKeyMapContext(ctx context.Context, mode string, lhs string, rhs string, opts map[string]bool) error {
+	return v.callContext(ctx, "nvim_set_keymap", nil, mode, lhs, rhs, opts)
+}
+
 // SetKeyMap sets a global |mapping| for the given mode.
 //
 // To set a buffer-local mapping, use SetBufferKeyMap().
@@ -1651,6 +2213,11 @@ func (v *Nvim) DeleteKeyMap(mode string, lhs string) error {
 	return v.call("nvim_del_keymap", nil, mode, lhs)
 }
 
+// DeleteKeyMapContext is like DeleteKeyMap, but uses ctx to cancel the request.
+func (v *Nvim) DeleteKeyMapContext(ctx context.Context, mode string, lhs string) error {
+	return v.callContext(ctx, "nvim_del_keymap", nil, mode, lhs)
+}
+
 // DeleteKeyMap unmaps a global mapping for the given mode.
 //
 // To unmap a buffer-local mapping, use DeleteBufferKeyMap().
@@ -1671,6 +2238,13 @@ func (v *Nvim) Commands(opts map[string]interface{}) (map[string]*Command, error
 	return result, err
 }
 
+// CommandsContext is like Commands, but uses ctx to cancel the request.
+func (v *Nvim) CommandsContext(ctx context.Context, opts map[string]interface{}) (map[string]*Command, error) {
+	var result map[string]*Command
+	err := v.callContext(ctx, "nvim_get_commands", &result, opts)
+	return result, err
+}
+
 // Commands gets a map of global (non-buffer-local) Ex commands.
 // Currently only user-commands are supported, not builtin Ex commands.
 //
@@ -1685,6 +2259,13 @@ func (v *Nvim) APIInfo() ([]interface{}, error) {
 	return result, err
 }
 
+// APIInfoContext is like APIInfo, but uses ctx to cancel the request.
+func (v *Nvim) APIInfoContext(ctx context.Context) ([]interface{}, error) {
+	var result []interface{}
+	err := v.callContext(ctx, "nvim_get_api_info", &result)
+	return result, err
+}
+
 func (b *Batch) APIInfo(result *[]interface{}) {
 	b.call("nvim_get_api_info", result)
 }
@@ -1697,6 +2278,11 @@ func (v *Nvim) SetClientInfo(name string, version *ClientVersion, typ string, me
 	return v.call("nvim_set_client_info", nil, name, version, typ, methods, attributes)
 }
 
+// SetClientInfoContext is like SetClientInfo, but uses ctx to cancel the request.
+func (v *Nvim) SetClientInfoContext(ctx context.Context, name string, version *ClientVersion, typ string, methods map[string]*ClientMethod, attributes ClientAttributes) error {
+	return v.callContext(ctx, "nvim_set_client_info", nil, name, version, typ, methods, attributes)
+}
+
 // SetClientInfo identify the client for nvim.
 //
 // Can be called more than once, but subsequent calls will remove earlier info, which should be resent if it is still valid.
@@ -1712,6 +2298,13 @@ func (v *Nvim) ChannelInfo(channel int) (*Channel, error) {
 	return result, err
 }
 
+// ChannelInfoContext is like ChannelInfo, but uses ctx to cancel the request.
+func (v *Nvim) ChannelInfoContext(ctx context.Context, channel int) (*Channel, error) {
+	var result *Channel
+	err := v.callContext(ctx, "nvim_get_chan_info", &result, channel)
+	return result, err
+}
+
 // ChannelInfo get information about a channel.
 func (b *Batch) ChannelInfo(channel int, result **Channel) {
 	b.call("nvim_get_chan_info", result, channel)
@@ -1724,6 +2317,13 @@ func (v *Nvim) Channels() ([]*Channel, error) {
 	return result, err
 }
 
+// ChannelsContext is like Channels, but uses ctx to cancel the request.
+func (v *Nvim) ChannelsContext(ctx context.Context) ([]*Channel, error) {
+	var result []*Channel
+	err := v.callContext(ctx, "nvim_list_chans", &result)
+	return result, err
+}
+
 // Channels get information about all open channels.
 func (b *Batch) Channels(result *[]*Channel) {
 	b.call("nvim_list_chans", result)
@@ -1736,6 +2336,13 @@ func (v *Nvim) ParseExpression(expr string, flags string, highlight bool) (map[s
 	return result, err
 }
 
+// ParseExpressionContext is like ParseExpression, but uses ctx to cancel the request.
+func (v *Nvim) ParseExpressionContext(ctx context.Context, expr string, flags string, highlight bool) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_parse_expression", &result, expr, flags, highlight)
+	return result, err
+}
+
 // ParseExpression parse a VimL expression.
 func (b *Batch) ParseExpression(expr string, flags string, highlight bool, result *map[string]interface{}) {
 	b.call("nvim_parse_expression", result, expr, flags, highlight)
@@ -1748,6 +2355,13 @@ func (v *Nvim) UIs() ([]*UI, error) {
 	return result, err
 }
 
+// UIsContext is like UIs, but uses ctx to cancel the request.
+func (v *Nvim) UIsContext(ctx context.Context) ([]*UI, error) {
+	var result []*UI
+	err := v.callContext(ctx, "nvim_list_uis", &result)
+	return result, err
+}
+
 // UIs gets a list of dictionaries representing attached UIs.
 func (b *Batch) UIs(result *[]*UI) {
 	b.call("nvim_list_uis", result)
@@ -1760,6 +2374,13 @@ func (v *Nvim) ProcChildren(pid int) ([]*Process, error) {
 	return result, err
 }
 
+// ProcChildrenContext is like ProcChildren, but uses ctx to cancel the request.
+func (v *Nvim) ProcChildrenContext(ctx context.Context, pid int) ([]*Process, error) {
+	var result []*Process
+	err := v.callContext(ctx, "nvim_get_proc_children", &result, pid)
+	return result, err
+}
+
 // ProcChildren gets the immediate children of process `pid`.
 func (b *Batch) ProcChildren(pid int, result *[]*Process) {
 	b.call("nvim_get_proc_children", result, pid)
@@ -1772,6 +2393,13 @@ func (v *Nvim) Proc(pid int) (Process, error) {
 	return result, err
 }
 
+// ProcContext is like Proc, but uses ctx to cancel the request.
+func (v *Nvim) ProcContext(ctx context.Context, pid int) (Process, error) {
+	var result Process
+	err := v.callContext(ctx, "nvim_get_proc", &result, pid)
+	return result, err
+}
+
 // Proc gets info describing process `pid`.
 func (b *Batch) Proc(pid int, result *Process) {
 	b.call("nvim_get_proc", result, pid)
@@ -1789,6 +2417,11 @@ func (v *Nvim) SelectPopupmenuItem(item int, insert bool, finish bool, opts map[
 	return v.call("nvim_select_popupmenu_item", nil, item, insert, finish, opts)
 }
 
+// SelectPopupmenuItemContext is like SelectPopupmenuItem, but uses ctx to cancel the request.
+func (v *Nvim) SelectPopupmenuItemContext(ctx context.Context, item int, insert bool, finish bool, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_select_popupmenu_item", nil, item, insert, finish, opts)
+}
+
 // SelectPopupmenuItem selects an item in the completion popupmenu.
 //
 // If |ins-completion| is not active this API call is silently ignored.
@@ -1808,6 +2441,13 @@ func (v *Nvim) WindowBuffer(window Window) (Buffer, error) {
 	return result, err
 }
 
+// WindowBufferContext is like WindowBuffer, but uses ctx to cancel the request.
+func (v *Nvim) WindowBufferContext(ctx context.Context, window Window) (Buffer, error) {
+	var result Buffer
+	err := v.callContext(ctx, "nvim_win_get_buf", &result, window)
+	return result, err
+}
+
 // WindowBuffer returns the current buffer in a window.
 func (b *Batch) WindowBuffer(window Window, result *Buffer) {
 	b.call("nvim_win_get_buf", result, window)
@@ -1818,6 +2458,11 @@ func (v *Nvim) SetBufferToWindow(window Window, buffer Buffer) error {
 	return v.call("nvim_win_set_buf", nil, window, buffer)
 }
 
+// SetBufferToWindowContext is like SetBufferToWindow, but uses ctx to cancel the request.
+func (v *Nvim) SetBufferToWindowContext(ctx context.Context, window Window, buffer Buffer) error {
+	return v.callContext(ctx, "nvim_win_set_buf", nil, window, buffer)
+}
+
 // SetBufferToWindow sets the current buffer in a window, without side-effects.
 func (b *Batch) SetBufferToWindow(window Window, buffer Buffer) {
 	b.call("nvim_win_set_buf", nil, window, buffer)
@@ -1830,6 +2475,13 @@ func (v *Nvim) WindowCursor(window Window) ([2]int, error) {
 	return result, err
 }
 
+// WindowCursorContext is like WindowCursor, but uses ctx to cancel the request.
+func (v *Nvim) WindowCursorContext(ctx context.Context, window Window) ([2]int, error) {
+	var result [2]int
+	err := v.callContext(ctx, "nvim_win_get_cursor", &result, window)
+	return result, err
+}
+
 // WindowCursor returns the cursor position in the window.
 func (b *Batch) WindowCursor(window Window, result *[2]int) {
 	b.call("nvim_win_get_cursor", result, window)
@@ -1840,6 +2492,11 @@ func (v *Nvim) SetWindowCursor(window Window, pos [2]int) error {
 	return v.call("nvim_win_set_cursor", nil, window, pos)
 }
 
+// SetWindowCursorContext is like SetWindowCursor, but uses ctx to cancel the request.
+func (v *Nvim) SetWindowCursorContext(ctx context.Context, window Window, pos [2]int) error {
+	return v.callContext(ctx, "nvim_win_set_cursor", nil, window, pos)
+}
+
 // SetWindowCursor sets the cursor position in the window to the given position.
 func (b *Batch) SetWindowCursor(window Window, pos [2]int) {
 	b.call("nvim_win_set_cursor", nil, window, pos)
@@ -1852,6 +2509,13 @@ func (v *Nvim) WindowHeight(window Window) (int, error) {
 	return result, err
 }
 
+// WindowHeightContext is like WindowHeight, but uses ctx to cancel the request.
+func (v *Nvim) WindowHeightContext(ctx context.Context, window Window) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_win_get_height", &result, window)
+	return result, err
+}
+
 // WindowHeight returns the window height.
 func (b *Batch) WindowHeight(window Window, result *int) {
 	b.call("nvim_win_get_height", result, window)
@@ -1862,6 +2526,11 @@ func (v *Nvim) SetWindowHeight(window Window, height int) error {
 	return v.call("nvim_win_set_height", nil, window, height)
 }
 
+// SetWindowHeightContext is like SetWindowHeight, but uses ctx to cancel the request.
+func (v *Nvim) SetWindowHeightContext(ctx context.Context, window Window, height int) error {
+	return v.callContext(ctx, "nvim_win_set_height", nil, window, height)
+}
+
 // SetWindowHeight sets the window height.
 func (b *Batch) SetWindowHeight(window Window, height int) {
 	b.call("nvim_win_set_height", nil, window, height)
@@ -1874,6 +2543,13 @@ func (v *Nvim) WindowWidth(window Window) (int, error) {
 	return result, err
 }
 
+// WindowWidthContext is like WindowWidth, but uses ctx to cancel the request.
+func (v *Nvim) WindowWidthContext(ctx context.Context, window Window) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_win_get_width", &result, window)
+	return result, err
+}
+
 // WindowWidth returns the window width.
 func (b *Batch) WindowWidth(window Window, result *int) {
 	b.call("nvim_win_get_width", result, window)
@@ -1884,6 +2560,11 @@ func (v *Nvim) SetWindowWidth(window Window, width int) error {
 	return v.call("nvim_win_set_width", nil, window, width)
 }
 
+// SetWindowWidthContext is like SetWindowWidth, but uses ctx to cancel the request.
+func (v *Nvim) SetWindowWidthContext(ctx context.Context, window Window, width int) error {
+	return v.callContext(ctx, "nvim_win_set_width", nil, window, width)
+}
+
 // SetWindowWidth sets the window width.
 func (b *Batch) SetWindowWidth(window Window, width int) {
 	b.call("nvim_win_set_width", nil, window, width)
@@ -1894,6 +2575,11 @@ func (v *Nvim) WindowVar(window Window, name string, result interface{}) error {
 	return v.call("nvim_win_get_var", result, window, name)
 }
 
+// WindowVarContext is like WindowVar, but uses ctx to cancel the request.
+func (v *Nvim) WindowVarContext(ctx context.Context, window Window, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_win_get_var", result, window, name)
+}
+
 // WindowVar gets a window-scoped (w:) variable.
 func (b *Batch) WindowVar(window Window, name string, result interface{}) {
 	b.call("nvim_win_get_var", result, window, name)
@@ -1904,6 +2590,11 @@ func (v *Nvim) SetWindowVar(window Window, name string, value interface{}) error
 	return v.call("nvim_win_set_var", nil, window, name, value)
 }
 
+// SetWindowVarContext is like SetWindowVar, but uses ctx to cancel the request.
+func (v *Nvim) SetWindowVarContext(ctx context.Context, window Window, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_win_set_var", nil, window, name, value)
+}
+
 // SetWindowVar sets a window-scoped (w:) variable.
 func (b *Batch) SetWindowVar(window Window, name string, value interface{}) {
 	b.call("nvim_win_set_var", nil, window, name, value)
@@ -1914,6 +2605,11 @@ func (v *Nvim) DeleteWindowVar(window Window, name string) error {
 	return v.call("nvim_win_del_var", nil, window, name)
 }
 
+// DeleteWindowVarContext is like DeleteWindowVar, but uses ctx to cancel the request.
+func (v *Nvim) DeleteWindowVarContext(ctx context.Context, window Window, name string) error {
+	return v.callContext(ctx, "nvim_win_del_var", nil, window, name)
+}
+
 // DeleteWindowVar removes a window-scoped (w:) variable.
 func (b *Batch) DeleteWindowVar(window Window, name string) {
 	b.call("nvim_win_del_var", nil, window, name)
@@ -1924,6 +2620,11 @@ func (v *Nvim) WindowOption(window Window, name string, result interface{}) erro
 	return v.call("nvim_win_get_option", result, window, name)
 }
 
+// WindowOptionContext is like WindowOption, but uses ctx to cancel the request.
+func (v *Nvim) WindowOptionContext(ctx context.Context, window Window, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_win_get_option", result, window, name)
+}
+
 // WindowOption gets a window option.
 func (b *Batch) WindowOption(window Window, name string, result interface{}) {
 	b.call("nvim_win_get_option", result, window, name)
@@ -1934,6 +2635,11 @@ func (v *Nvim) SetWindowOption(window Window, name string, value interface{}) er
 	return v.call("nvim_win_set_option", nil, window, name, value)
 }
 
+// SetWindowOptionContext is like SetWindowOption, but uses ctx to cancel the request.
+func (v *Nvim) SetWindowOptionContext(ctx context.Context, window Window, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_win_set_option", nil, window, name, value)
+}
+
 // SetWindowOption sets a window option.
 func (b *Batch) SetWindowOption(window Window, name string, value interface{}) {
 	b.call("nvim_win_set_option", nil, window, name, value)
@@ -1946,6 +2652,13 @@ func (v *Nvim) WindowPosition(window Window) ([2]int, error) {
 	return result, err
 }
 
+// WindowPositionContext is like WindowPosition, but uses ctx to cancel the request.
+func (v *Nvim) WindowPositionContext(ctx context.Context, window Window) ([2]int, error) {
+	var result [2]int
+	err := v.callContext(ctx, "nvim_win_get_position", &result, window)
+	return result, err
+}
+
 // WindowPosition gets the window position in display cells. First position is zero.
 func (b *Batch) WindowPosition(window Window, result *[2]int) {
 	b.call("nvim_win_get_position", result, window)
@@ -1958,6 +2671,13 @@ func (v *Nvim) WindowTabpage(window Window) (Tabpage, error) {
 	return result, err
 }
 
+// WindowTabpageContext is like WindowTabpage, but uses ctx to cancel the request.
+func (v *Nvim) WindowTabpageContext(ctx context.Context, window Window) (Tabpage, error) {
+	var result Tabpage
+	err := v.callContext(ctx, "nvim_win_get_tabpage", &result, window)
+	return result, err
+}
+
 // WindowTabpage gets the tab page that contains the window.
 func (b *Batch) WindowTabpage(window Window, result *Tabpage) {
 	b.call("nvim_win_get_tabpage", result, window)
@@ -1970,6 +2690,13 @@ func (v *Nvim) WindowNumber(window Window) (int, error) {
 	return result, err
 }
 
+// WindowNumberContext is like WindowNumber, but uses ctx to cancel the request.
+func (v *Nvim) WindowNumberContext(ctx context.Context, window Window) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_win_get_number", &result, window)
+	return result, err
+}
+
 // WindowNumber gets the window number from the window handle.
 func (b *Batch) WindowNumber(window Window, result *int) {
 	b.call("nvim_win_get_number", result, window)
@@ -1982,6 +2709,13 @@ func (v *Nvim) IsWindowValid(window Window) (bool, error) {
 	return result, err
 }
 
+// IsWindowValidContext is like IsWindowValid, but uses ctx to cancel the request.
+func (v *Nvim) IsWindowValidContext(ctx context.Context, window Window) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_win_is_valid", &result, window)
+	return result, err
+}
+
 // IsWindowValid returns true if the window is valid.
 func (b *Batch) IsWindowValid(window Window, result *bool) {
 	b.call("nvim_win_is_valid", result, window)
@@ -2000,6 +2734,11 @@ func (v *Nvim) SetWindowConfig(window Window, config map[string]interface{}) err
 	return v.call("nvim_win_set_config", nil, window, config)
 }
 
+// SetWindowConfigContext is like SetWindowConfig, but uses ctx to cancel the request.
+func (v *Nvim) SetWindowConfigContext(ctx context.Context, window Window, config map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_win_set_config", nil, window, config)
+}
+
 // SetWindowConfig configure window position. Currently this is only used to configure
 // floating and external windows (including changing a split window to these
 // types).
@@ -2025,6 +2764,13 @@ func (v *Nvim) WindowConfig(window Window) (map[string]interface{}, error) {
 	return result, err
 }
 
+// WindowConfigContext is like WindowConfig, but uses ctx to cancel the request.
+func (v *Nvim) WindowConfigContext(ctx context.Context, window Window) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_win_get_config", &result, window)
+	return result, err
+}
+
 // WindowConfig return window configuration.
 //
 // Return a dictionary containing the same config that can be given to
@@ -2042,6 +2788,11 @@ func (v *Nvim) CloseWindow(window Window, force bool) error {
 	return v.call("nvim_win_close", nil, window, force)
 }
 
+// CloseWindowContext is like CloseWindow, but uses ctx to cancel the request.
+func (v *Nvim) CloseWindowContext(ctx context.Context, window Window, force bool) error {
+	return v.callContext(ctx, "nvim_win_close", nil, window, force)
+}
+
 // CloseWindow close a window.
 //
 // This is equivalent to |:close| with count except that it takes a window id.
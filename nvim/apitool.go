@@ -166,6 +166,7 @@ var implementationTemplate = template.Must(template.New("").Funcs(template.FuncM
 package nvim
 
 import (
+    "context"
     "fmt"
 
     "github.com/neovim/go-client/msgpack"
@@ -215,6 +216,11 @@ func (v *Nvim) {{.GoName}}({{range .Parameters}}{{.Name}} {{.Type}},{{end}} resu
     return v.call("{{.Name}}", result, {{range .Parameters}}{{.Name}},{{end}})
 }
 
+// {{.GoName}}Context is like {{.GoName}}, but uses ctx to cancel the request.
+func (v *Nvim) {{.GoName}}Context(ctx context.Context, {{range .Parameters}}{{.Name}} {{.Type}},{{end}} result interface{}) error {
+    return v.callContext(ctx, "{{.Name}}", result, {{range .Parameters}}{{.Name}},{{end}})
+}
+
 {{.Doc}}
 func (b *Batch) {{.GoName}}({{range .Parameters}}{{.Name}} {{.Type}},{{end}} result interface{}) {
     b.call("{{.Name}}", result, {{range .Parameters}}{{.Name}},{{end}})
@@ -227,6 +233,13 @@ func (v *Nvim) {{.GoName}}({{range .Parameters}}{{.Name}} {{.Type}},{{end}}) ({{
     err := v.call("{{.Name}}", &result, {{range .Parameters}}{{.Name}},{{end}})
     return {{if .ReturnPtr}}&{{end}}result, err
 }
+
+// {{.GoName}}Context is like {{.GoName}}, but uses ctx to cancel the request.
+func (v *Nvim) {{.GoName}}Context(ctx context.Context, {{range .Parameters}}{{.Name}} {{.Type}},{{end}}) ({{if .ReturnPtr}}*{{end}}{{.ReturnType}}, error) {
+    var result {{.ReturnType}}
+    err := v.callContext(ctx, "{{.Name}}", &result, {{range .Parameters}}{{.Name}},{{end}})
+    return {{if .ReturnPtr}}&{{end}}result, err
+}
 {{.Doc}}
 func (b *Batch) {{.GoName}}({{range .Parameters}}{{.Name}} {{.Type}},{{end}} result *{{.ReturnType}}) {
     b.call("{{.Name}}", result, {{range .Parameters}}{{.Name}},{{end}})
@@ -236,6 +249,11 @@ func (b *Batch) {{.GoName}}({{range .Parameters}}{{.Name}} {{.Type}},{{end}} res
 func (v *Nvim) {{.GoName}}({{range .Parameters}}{{.Name}} {{.Type}},{{end}}) error {
     return v.call("{{.Name}}", nil, {{range .Parameters}}{{.Name}},{{end}})
 }
+
+// {{.GoName}}Context is like {{.GoName}}, but uses ctx to cancel the request.
+func (v *Nvim) {{.GoName}}Context(ctx context.Context, {{range .Parameters}}{{.Name}} {{.Type}},{{end}}) error {
+    return v.callContext(ctx, "{{.Name}}", nil, {{range .Parameters}}{{.Name}},{{end}})
+}
 {{.Doc}}
 func (b *Batch) {{.GoName}}({{range .Parameters}}{{.Name}} {{.Type}},{{end}}) {
     b.call("{{.Name}}", nil, {{range .Parameters}}{{.Name}},{{end}})
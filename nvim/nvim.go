@@ -10,6 +10,7 @@ import (
 	"net"
 	"os/exec"
 	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"syscall"
@@ -23,6 +24,8 @@ import (
 
 var embedProcAttr *syscall.SysProcAttr
 
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
 // Nvim represents a remote instance of Nvim. It is safe to call Nvim methods
 // concurrently.
 type Nvim struct {
@@ -63,16 +66,31 @@ func (v *Nvim) startServe() {
 
 // Close releases the resources used the client.
 func (v *Nvim) Close() error {
+	return v.close(v.ep.Close)
+}
+
+// Shutdown gracefully shuts down the client. Shutdown stops handling new
+// requests and notifications from Nvim, waits for running handlers to
+// complete and then releases the resources used by the client. If ctx is done
+// before the handlers complete, the client is closed and Shutdown returns
+// ctx.Err().
+//
+// Shutdown waits for all handlers to complete. Do not call Shutdown from a
+// handler.
+func (v *Nvim) Shutdown(ctx context.Context) error {
+	return v.close(func() error { return v.ep.Shutdown(ctx) })
+}
+
+func (v *Nvim) close(closeEndpoint func() error) error {
+	err := closeEndpoint()
 
 	if v.cmd != nil && v.cmd.Process != nil {
-		// The child process should exit cleanly on call to v.ep.Close(). Kill
-		// the process if it does not exit as expected.
+		// The child process should exit cleanly on close of the endpoint.
+		// Kill the process if it does not exit as expected.
 		t := time.AfterFunc(10*time.Second, func() { v.cmd.Process.Kill() })
 		defer t.Stop()
 	}
 
-	err := v.ep.Close()
-
 	if v.cmd != nil {
 		v.readMu.Lock()
 		defer v.readMu.Unlock()
@@ -116,6 +134,25 @@ func New(r io.Reader, w io.Writer, c io.Closer, logf func(string, ...interface{}
 	return &Nvim{ep: ep}, nil
 }
 
+// EndpointOptions returns the options required by endpoints that communicate
+// with Nvim. Pass the options to rpc.WithEndpointOptions when serving Nvim
+// connections with an rpc.Server.
+func EndpointOptions() []rpc.Option {
+	return []rpc.Option{withExtensions()}
+}
+
+// FromEndpoint returns an Nvim client that uses endpoint ep. The endpoint
+// must be created with the options returned from EndpointOptions. The
+// application is responsible for serving and closing the endpoint.
+//
+// Use FromEndpoint to call Nvim from handlers registered with an rpc.Server:
+//
+//  e, _ := rpc.EndpointFromContext(ctx)
+//  v := nvim.FromEndpoint(e)
+func FromEndpoint(ep *rpc.Endpoint) *Nvim {
+	return &Nvim{ep: ep}
+}
+
 // ChildProcessOption specifies an option for creating a child process.
 type ChildProcessOption struct {
 	f func(*childProcessOptions)
@@ -362,12 +399,13 @@ func Dial(address string, options ...DialOption) (*Nvim, error) {
 // RegisterHandler registers fn as a MessagePack RPC handler for the named
 // method. The function signature for fn is one of
 //
-//  func([v *nvim.Nvim,] {args}) ({resultType}, error)
-//  func([v *nvim.Nvim,] {args}) error
-//  func([v *nvim.Nvim,] {args})
+//  func([ctx context.Context,] [v *nvim.Nvim,] {args}) ({resultType}, error)
+//  func([ctx context.Context,] [v *nvim.Nvim,] {args}) error
+//  func([ctx context.Context,] [v *nvim.Nvim,] {args})
 //
 // where {args} is zero or more arguments and {resultType} is the type of a
-// return value. Call the handler from Nvim using the rpcnotify and rpcrequest
+// return value. The optional ctx is cancelled when the client is closed or
+// Serve returns. Call the handler from Nvim using the rpcnotify and rpcrequest
 // functions:
 //
 //  :help rpcrequest()
@@ -378,12 +416,43 @@ func Dial(address string, options ...DialOption) (*Nvim, error) {
 func (v *Nvim) RegisterHandler(method string, fn interface{}) error {
 	var args []interface{}
 	t := reflect.TypeOf(fn)
-	if t.Kind() == reflect.Func && t.NumIn() > 0 && t.In(0) == reflect.TypeOf(v) {
-		args = append(args, v)
+	if t.Kind() == reflect.Func {
+		i := 0
+		if t.NumIn() > 0 && t.In(0) == contextType {
+			i++
+		}
+		if t.NumIn() > i && t.In(i) == reflect.TypeOf(v) {
+			args = append(args, v)
+		}
 	}
 	return v.ep.Register(method, fn, args...)
 }
 
+// UnregisterHandler removes the handler registered for the named method.
+func (v *Nvim) UnregisterHandler(method string) {
+	v.ep.Unregister(method)
+}
+
+// RegisteredHandlers returns the sorted names of the methods with registered
+// handlers.
+func (v *Nvim) RegisteredHandlers() []string {
+	return v.ep.Methods()
+}
+
+// SetFallbackHandler sets the handler for methods without a registered
+// handler. By default, requests for unknown methods are answered with an error
+// and notifications for unknown methods are dropped.
+func (v *Nvim) SetFallbackHandler(fn rpc.FallbackHandler) {
+	v.ep.SetFallbackHandler(fn)
+}
+
+// SetHandlerDispatchMode sets how notifications for the named method are
+// dispatched to the handler registered with RegisterHandler. By default, all
+// notifications are handled in order in a single goroutine.
+func (v *Nvim) SetHandlerDispatchMode(method string, mode rpc.DispatchMode) {
+	v.ep.SetDispatchMode(method, mode)
+}
+
 // ChannelID returns Nvim's channel id for this client.
 func (v *Nvim) ChannelID() int {
 	v.channelIDMu.Lock()
@@ -406,6 +475,10 @@ func (v *Nvim) call(sm string, result interface{}, args ...interface{}) error {
 	return fixError(sm, v.ep.Call(sm, result, args...))
 }
 
+func (v *Nvim) callContext(ctx context.Context, sm string, result interface{}, args ...interface{}) error {
+	return fixError(sm, v.ep.CallContext(ctx, sm, result, args...))
+}
+
 // NewBatch creates a new batch.
 func (v *Nvim) NewBatch() *Batch {
 	b := &Batch{ep: v.ep}
@@ -437,6 +510,11 @@ type Batch struct {
 
 // Execute executes the API function calls in the batch.
 func (b *Batch) Execute() error {
+	return b.ExecuteContext(context.Background())
+}
+
+// ExecuteContext is like Execute, but uses ctx to cancel the request.
+func (b *Batch) ExecuteContext(ctx context.Context) error {
 	defer func() {
 		b.buf.Reset()
 		b.sms = b.sms[:0]
@@ -460,7 +538,7 @@ func (b *Batch) Execute() error {
 		nil,
 	}
 
-	err := b.ep.Call("nvim_call_atomic", &result, &batchArg{n: len(b.sms), p: b.buf.Bytes()})
+	err := b.ep.CallContext(ctx, "nvim_call_atomic", &result, &batchArg{n: len(b.sms), p: b.buf.Bytes()})
 	if err != nil {
 		return err
 	}
@@ -474,13 +552,13 @@ func (b *Batch) Execute() error {
 		(e.Type != exceptionError && e.Type != validationError) {
 		return fmt.Errorf("nvim:nvim_call_atomic %d %d %s", e.Index, e.Type, e.Message)
 	}
-	errorType := "exception"
-	if e.Type == validationError {
-		errorType = "validation"
-	}
 	return &BatchError{
 		Index: e.Index,
-		Err:   fmt.Errorf("nvim:%s %s: %s", b.sms[e.Index], errorType, e.Message),
+		Err: &APIError{
+			Method:  b.sms[e.Index],
+			Type:    APIErrorType(e.Type),
+			Message: e.Message,
+		},
 	}
 }
 
@@ -516,7 +594,8 @@ type BatchError struct {
 	// error.
 	Index int
 
-	// Err is the error.
+	// Err is the error. Err is an *APIError when the error is returned from
+	// the API function.
 	Err error
 }
 
@@ -524,14 +603,72 @@ func (e *BatchError) Error() string {
 	return e.Err.Error()
 }
 
+// Unwrap returns the underlying error.
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// APIErrorType is the type of an error returned from an API function.
+type APIErrorType int
+
+const (
+	// ExceptionError is the type of errors raised while executing the API
+	// function, such as errors from Ex commands.
+	ExceptionError APIErrorType = exceptionError
+
+	// ValidationError is the type of errors caused by invalid arguments.
+	ValidationError APIErrorType = validationError
+)
+
+func (t APIErrorType) String() string {
+	switch t {
+	case ExceptionError:
+		return "exception"
+	case ValidationError:
+		return "validation"
+	default:
+		return fmt.Sprintf("APIErrorType(%d)", int(t))
+	}
+}
+
+// APIError represents an error returned from an API function.
+type APIError struct {
+	// Method is the name of the API function.
+	Method string
+
+	// Type is the type of the error.
+	Type APIErrorType
+
+	// Message is the error message.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("nvim:%s %s: %s", e.Method, e.Type, e.Message)
+}
+
+var vimErrorCodePattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9])(E\d+):`)
+
+// Code returns the Vim error code in the error message, for example "E492".
+// Code returns "" if the message does not contain an error code.
+//
+//  :help error-messages
+func (e *APIError) Code() string {
+	m := vimErrorCodePattern.FindStringSubmatch(e.Message)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
 func fixError(sm string, err error) error {
 	if e, ok := err.(rpc.Error); ok {
 		if a, ok := e.Value.([]interface{}); ok && len(a) == 2 {
 			switch a[0] {
 			case int64(exceptionError), uint64(exceptionError):
-				return fmt.Errorf("nvim:%s exception: %v", sm, a[1])
+				return &APIError{Method: sm, Type: ExceptionError, Message: fmt.Sprint(a[1])}
 			case int64(validationError), uint64(validationError):
-				return fmt.Errorf("nvim:%s validation: %v", sm, a[1])
+				return &APIError{Method: sm, Type: ValidationError, Message: fmt.Sprint(a[1])}
 			}
 		}
 	}
@@ -550,6 +687,11 @@ func (v *Nvim) Request(procedure string, result interface{}, args ...interface{}
 	return v.call(procedure, result, args...)
 }
 
+// RequestContext is like Request, but uses ctx to cancel the request.
+func (v *Nvim) RequestContext(ctx context.Context, procedure string, result interface{}, args ...interface{}) error {
+	return v.callContext(ctx, procedure, result, args...)
+}
+
 // Call calls a vimscript function.
 func (v *Nvim) Call(fname string, result interface{}, args ...interface{}) error {
 	if args == nil {
@@ -558,6 +700,14 @@ func (v *Nvim) Call(fname string, result interface{}, args ...interface{}) error
 	return v.call("nvim_call_function", result, fname, args)
 }
 
+// CallContext is like Call, but uses ctx to cancel the request.
+func (v *Nvim) CallContext(ctx context.Context, fname string, result interface{}, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	return v.callContext(ctx, "nvim_call_function", result, fname, args)
+}
+
 // Request makes a RPC request atomically as a part of batch request.
 func (b *Batch) Request(procedure string, result interface{}, args ...interface{}) {
 	b.call(procedure, result, args...)
@@ -579,6 +729,14 @@ func (v *Nvim) CallDict(dict []interface{}, fname string, result interface{}, ar
 	return v.call("nvim_call_dict_function", result, fname, dict, args)
 }
 
+// CallDictContext is like CallDict, but uses ctx to cancel the request.
+func (v *Nvim) CallDictContext(ctx context.Context, dict []interface{}, fname string, result interface{}, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	return v.callContext(ctx, "nvim_call_dict_function", result, fname, dict, args)
+}
+
 // CallDict calls a vimscript Dictionary function.
 func (b *Batch) CallDict(dict []interface{}, fname string, result interface{}, args ...interface{}) {
 	if args == nil {
@@ -595,6 +753,14 @@ func (v *Nvim) ExecuteLua(code string, result interface{}, args ...interface{})
 	return v.call("nvim_execute_lua", result, code, args)
 }
 
+// ExecuteLuaContext is like ExecuteLua, but uses ctx to cancel the request.
+func (v *Nvim) ExecuteLuaContext(ctx context.Context, code string, result interface{}, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	return v.callContext(ctx, "nvim_execute_lua", result, code, args)
+}
+
 // ExecuteLua executes a Lua block.
 func (b *Batch) ExecuteLua(code string, result interface{}, args ...interface{}) {
 	if args == nil {
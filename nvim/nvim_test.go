@@ -10,6 +10,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/neovim/go-client/msgpack/rpc"
 )
 
 func newChildProcess(t *testing.T) (*Nvim, func()) {
@@ -291,6 +293,8 @@ func TestAPI(t *testing.T) {
 		err := b.Execute()
 		if e, ok := err.(*BatchError); !ok || e.Index != errorIndex {
 			t.Errorf("unxpected error %T %v", e, e)
+		} else if ae, ok := e.Unwrap().(*APIError); !ok || ae.Method != "nvim_get_var" {
+			t.Errorf("unexpected wrapped error %T %v", e.Err, e.Err)
 		}
 		// Expect results proceeding error.
 		for i := 0; i < errorIndex; i++ {
@@ -619,6 +623,33 @@ func TestDial(t *testing.T) {
 	}
 }
 
+func TestAPIError(t *testing.T) {
+	err := fixError("nvim_command", rpc.Error{Value: []interface{}{int64(exceptionError), "Vim:E492: Not an editor command: foo"}})
+	e, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("fixError returned %T, want *APIError", err)
+	}
+	if e.Method != "nvim_command" || e.Type != ExceptionError || e.Message != "Vim:E492: Not an editor command: foo" {
+		t.Errorf("fixError returned %+v", e)
+	}
+	if got, want := e.Error(), "nvim:nvim_command exception: Vim:E492: Not an editor command: foo"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got, want := e.Code(), "E492"; got != want {
+		t.Errorf("Code() = %q, want %q", got, want)
+	}
+
+	err = fixError("nvim_buf_get_lines", rpc.Error{Value: []interface{}{uint64(validationError), "Index out of bounds"}})
+	if e, ok := err.(*APIError); !ok || e.Type != ValidationError || e.Code() != "" {
+		t.Errorf("fixError returned %T %v, want validation *APIError without code", err, err)
+	}
+
+	rpcErr := rpc.Error{Value: "other"}
+	if err := fixError("nvim_command", rpcErr); err != rpcErr {
+		t.Errorf("fixError returned %v, want %v", err, rpcErr)
+	}
+}
+
 func TestEmbedded(t *testing.T) {
 	v, err := NewEmbedded(&EmbedOptions{
 		Args: []string{"-u", "NONE", "-n"},
@@ -68,13 +68,13 @@ func (p *Plugin) handle(fn interface{}, spec *pluginSpec) {
 // Handle registers fn as a MessagePack RPC handler for the specified method
 // name. The function signature for fn is one of
 //
-//  func([v *nvim.Nvim,] {args}) ({resultType}, error)
-//  func([v *nvim.Nvim,] {args}) error
-//  func([v *nvim.Nvim,] {args})
+//  func([ctx context.Context,] [v *nvim.Nvim,] {args}) ({resultType}, error)
+//  func([ctx context.Context,] [v *nvim.Nvim,] {args}) error
+//  func([ctx context.Context,] [v *nvim.Nvim,] {args})
 //
 // where {args} is zero or more arguments and {resultType} is the type of of a
-// return value. Call the handler from Nvim using the rpcnotify and rpcrequest
-// functions:
+// return value. The optional ctx is cancelled when the client is closed.
+// Call the handler from Nvim using the rpcnotify and rpcrequest functions:
 //
 //  :help rpcrequest()
 //  :help rpcnotify()
@@ -101,8 +101,8 @@ type FunctionOptions struct {
 // HandleFunction registers fn as a handler for a Nvim function. The function
 // signature for fn is one of
 //
-//  func([v *nvim.Nvim,] args {arrayType} [, eval {evalType}]) ({resultType}, error)
-//  func([v *nvim.Nvim,] args {arrayType} [, eval {evalType}]) error
+//  func([ctx context.Context,] [v *nvim.Nvim,] args {arrayType} [, eval {evalType}]) ({resultType}, error)
+//  func([ctx context.Context,] [v *nvim.Nvim,] args {arrayType} [, eval {evalType}]) error
 //
 // where {arrayType} is a type that can be unmarshaled from a MessagePack
 // array, {evalType} is a type compatible with the Eval option expression and
@@ -210,6 +210,7 @@ type CommandOptions struct {
 // HandleCommand registers fn as a handler for a Nvim command. The arguments
 // to the function fn are:
 //
+//  ctx context.Context optional
 //  v *nvim.Nvim        optional
 //  args []string       when options.NArgs != ""
 //  range [2]int        when options.Range == "." or Range == "%"